@@ -0,0 +1,143 @@
+// Package agents 让模型通过调用工具来完成任务：模型决定调用哪个工具、传入什么参数，
+// Executor 执行工具并把结果交还给模型，直到模型给出最终答案。
+//
+//	agent := agents.NewReActAgent(llm, []agents.Tool{calculator})
+//	executor := agents.NewExecutor(agent, agents.WithMaxIterations(5))
+//	answer, err := chains.Run(ctx, executor, "3 的 7 次方是多少？")
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+const (
+	// DefaultInputKey 是 agent 默认的输入键。
+	DefaultInputKey = "input"
+	// DefaultOutputKey 是 agent 默认的输出键。
+	DefaultOutputKey = "output"
+)
+
+var (
+	// ErrUnableToParseOutput 表示无法从模型输出中解析出工具调用或最终答案。
+	ErrUnableToParseOutput = errors.New("agents: unable to parse agent output")
+	// ErrNotFinished 表示达到最大迭代次数时 agent 仍然没有给出最终答案。
+	ErrNotFinished = errors.New("agents: agent did not finish within the maximum number of iterations")
+	// ErrToolsNotSupported 表示模型不支持原生的工具调用。
+	ErrToolsNotSupported = errors.New("agents: model does not support tool calling")
+)
+
+// Tool 是 agent 可以调用的工具。
+type Tool interface {
+	// Name 是工具的名称，模型通过名称选择工具。
+	Name() string
+	// Description 告诉模型工具的用途和输入格式。
+	Description() string
+	// Call 执行工具并返回给模型看的结果。
+	Call(ctx context.Context, input string) (string, error)
+}
+
+// StructuredTool 是输入为 JSON 对象的工具。
+// 使用原生工具调用的 agent 会把 Parameters 作为参数的 JSON Schema 发给模型，
+// 并把模型生成的 JSON 参数原样传给 Call。
+type StructuredTool interface {
+	Tool
+	// Parameters 返回参数的 JSON Schema。
+	Parameters() any
+}
+
+// AgentAction 是 agent 决定执行的一次工具调用。
+type AgentAction struct {
+	Tool      string // 工具名称
+	ToolInput string // 传给工具的输入
+	Log       string // 模型产生这次调用时的原始输出
+	// ToolCallID 是原生工具调用的 ID，文本解析得到的调用为空。
+	ToolCallID string
+	// ToolCallIndex 是这次调用在同一次模型响应的所有原生工具调用中的序号，从 0 开始。
+	// 模型一次返回多个工具调用时，FunctionsAgent 据此把它们放回同一条助手消息。
+	ToolCallIndex int
+}
+
+// AgentStep 是一次工具调用以及工具返回的结果。
+type AgentStep struct {
+	Action      AgentAction
+	Observation string
+}
+
+// AgentFinish 是 agent 给出的最终结果。
+type AgentFinish struct {
+	ReturnValues map[string]any
+	Log          string // 模型给出最终答案时的原始输出
+}
+
+// Agent 根据输入和已经执行过的步骤，决定下一步调用哪些工具，或者给出最终结果。
+type Agent interface {
+	// Plan 返回要执行的工具调用，或者在任务完成时返回 AgentFinish。
+	Plan(ctx context.Context, steps []AgentStep, inputs map[string]any, opts ...llms.CallOption) ([]AgentAction, *AgentFinish, error)
+	GetInputKeys() []string
+	GetOutputKeys() []string
+	GetTools() []Tool
+}
+
+// ParseError 记录无法解析的模型输出，它包装了 ErrUnableToParseOutput。
+type ParseError struct {
+	Output string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v: %q", ErrUnableToParseOutput, e.Output)
+}
+
+func (e *ParseError) Unwrap() error {
+	return ErrUnableToParseOutput
+}
+
+// Option 是用于配置 agent 的函数选项。
+type Option func(*options)
+
+type options struct {
+	prompt        string
+	systemMessage string
+	outputKey     string
+	callOptions   []llms.CallOption
+}
+
+func newOptions(opts []Option) options {
+	o := options{outputKey: DefaultOutputKey}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithPrompt 设置 ReActAgent 的提示词模板（Go text/template 语法），
+// 模板可以使用 input、tools、tool_names 和 agent_scratchpad 四个变量。
+func WithPrompt(prompt string) Option {
+	return func(o *options) {
+		o.prompt = prompt
+	}
+}
+
+// WithSystemMessage 设置 FunctionsAgent 的系统消息。
+func WithSystemMessage(message string) Option {
+	return func(o *options) {
+		o.systemMessage = message
+	}
+}
+
+// WithOutputKey 设置最终答案的输出键，默认为 DefaultOutputKey。
+func WithOutputKey(key string) Option {
+	return func(o *options) {
+		o.outputKey = key
+	}
+}
+
+// WithCallOptions 设置 agent 每次调用模型时都会带上的选项，例如温度。
+func WithCallOptions(opts ...llms.CallOption) Option {
+	return func(o *options) {
+		o.callOptions = append(o.callOptions, opts...)
+	}
+}
@@ -0,0 +1,188 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zideajang/langChaingo/callbacks"
+	"github.com/zideajang/langChaingo/chains"
+	"github.com/zideajang/langChaingo/llms"
+)
+
+const (
+	// DefaultMaxIterations 是 Executor 默认的最大迭代次数。
+	DefaultMaxIterations = 15
+	// IntermediateStepsKey 是 Executor 返回中间步骤时使用的输出键，值的类型是 []AgentStep。
+	IntermediateStepsKey = "intermediate_steps"
+)
+
+// Executor 反复调用 Agent 规划下一步并执行工具，直到 agent 给出最终答案。
+// Executor 实现了 chains.Chain，可以和其他链组合使用。
+type Executor struct {
+	Agent Agent
+	// MaxIterations 是最多规划的次数，达到后返回 ErrNotFinished。
+	MaxIterations int
+	// Timeout 是整个执行过程的超时时间，0 表示不限制。
+	Timeout time.Duration
+	// ReturnIntermediateSteps 为 true 时在输出中以 IntermediateStepsKey 返回所有中间步骤。
+	ReturnIntermediateSteps bool
+	// HandleParsingErrors 为 true 时把无法解析的输出作为观察结果反馈给模型，让它重新按格式输出。
+	HandleParsingErrors bool
+	// OnStep 在每个工具执行完成后被调用，可以用来记录执行过程。
+	OnStep func(ctx context.Context, step AgentStep)
+}
+
+var _ chains.Chain = (*Executor)(nil)
+
+// ExecutorOption 是用于配置 Executor 的函数选项。
+type ExecutorOption func(*Executor)
+
+// WithMaxIterations 设置最大迭代次数，默认为 DefaultMaxIterations。
+func WithMaxIterations(n int) ExecutorOption {
+	return func(e *Executor) {
+		e.MaxIterations = n
+	}
+}
+
+// WithTimeout 设置整个执行过程的超时时间。
+func WithTimeout(timeout time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.Timeout = timeout
+	}
+}
+
+// WithReturnIntermediateSteps 设置在输出中返回所有中间步骤。
+func WithReturnIntermediateSteps() ExecutorOption {
+	return func(e *Executor) {
+		e.ReturnIntermediateSteps = true
+	}
+}
+
+// WithHandleParsingErrors 设置把无法解析的输出反馈给模型，而不是直接返回错误。
+func WithHandleParsingErrors() ExecutorOption {
+	return func(e *Executor) {
+		e.HandleParsingErrors = true
+	}
+}
+
+// WithOnStep 设置每个工具执行完成后的回调。
+func WithOnStep(fn func(ctx context.Context, step AgentStep)) ExecutorOption {
+	return func(e *Executor) {
+		e.OnStep = fn
+	}
+}
+
+// NewExecutor 创建一个执行 agent 的 Executor。
+func NewExecutor(agent Agent, opts ...ExecutorOption) *Executor {
+	e := &Executor{Agent: agent, MaxIterations: DefaultMaxIterations}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+func (e *Executor) Call(ctx context.Context, inputs map[string]any, opts ...llms.CallOption) (map[string]any, error) {
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+
+	h := callbacks.FromContext(ctx)
+	var steps []AgentStep
+	for i := 0; i < e.MaxIterations; i++ {
+		actions, finish, err := e.Agent.Plan(ctx, steps, inputs, opts...)
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) && e.HandleParsingErrors {
+			step := AgentStep{
+				Action:      AgentAction{Tool: "_Exception", ToolInput: parseErr.Output, Log: parseErr.Output},
+				Observation: "Invalid format. Either give an Action and Action Input, or a Final Answer.",
+			}
+			steps = append(steps, step)
+			e.onStep(ctx, step)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if finish != nil {
+			if h != nil {
+				h.OnAgentFinish(ctx, finish.ReturnValues, finish.Log)
+			}
+			return e.result(finish.ReturnValues, steps), nil
+		}
+
+		for _, action := range actions {
+			if h != nil {
+				h.OnAgentAction(ctx, action.Tool, action.ToolInput, action.Log)
+			}
+			step := AgentStep{Action: action, Observation: e.runTool(ctx, h, action)}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+			e.onStep(ctx, step)
+		}
+	}
+	return nil, fmt.Errorf("%w (%d iterations)", ErrNotFinished, e.MaxIterations)
+}
+
+func (e *Executor) GetInputKeys() []string {
+	return e.Agent.GetInputKeys()
+}
+
+func (e *Executor) GetOutputKeys() []string {
+	keys := e.Agent.GetOutputKeys()
+	if e.ReturnIntermediateSteps {
+		keys = append(append([]string{}, keys...), IntermediateStepsKey)
+	}
+	return keys
+}
+
+// runTool 执行工具。工具不存在或者执行失败时把原因作为观察结果返回，让模型有机会修正。
+// h 不为 nil 时发送工具的开始、结束和错误事件。
+func (e *Executor) runTool(ctx context.Context, h callbacks.Handler, action AgentAction) string {
+	tools := e.Agent.GetTools()
+	i := slices.IndexFunc(tools, func(t Tool) bool { return t.Name() == action.Tool })
+	if i < 0 {
+		return fmt.Sprintf("%s is not a valid tool, try one of [%s].",
+			action.Tool, strings.Join(toolNames(tools), ", "))
+	}
+	tool := tools[i]
+
+	if h != nil {
+		h.OnToolStart(ctx, tool.Name(), action.ToolInput)
+	}
+	observation, err := tool.Call(ctx, action.ToolInput)
+	if err != nil {
+		if h != nil {
+			h.OnToolError(ctx, tool.Name(), err)
+		}
+		return "Error: " + err.Error()
+	}
+	if h != nil {
+		h.OnToolEnd(ctx, tool.Name(), observation)
+	}
+	return observation
+}
+
+func (e *Executor) onStep(ctx context.Context, step AgentStep) {
+	if e.OnStep != nil {
+		e.OnStep(ctx, step)
+	}
+}
+
+func (e *Executor) result(values map[string]any, steps []AgentStep) map[string]any {
+	if !e.ReturnIntermediateSteps {
+		return values
+	}
+	out := make(map[string]any, len(values)+1)
+	maps.Copy(out, values)
+	out[IntermediateStepsKey] = steps
+	return out
+}
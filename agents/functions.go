@@ -0,0 +1,169 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// DefaultFunctionsSystemMessage 是 FunctionsAgent 默认的系统消息。
+const DefaultFunctionsSystemMessage = "You are a helpful assistant. Use the provided tools when they help answer the question."
+
+// defaultToolParameters 是没有实现 StructuredTool 的工具使用的参数 schema：一个字符串参数 input。
+var defaultToolParameters = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"input": map[string]any{"type": "string", "description": "the input to the tool"},
+	},
+	"required": []string{"input"},
+}
+
+// FunctionsAgent 使用模型原生的工具调用能力选择工具，不需要解析文本，
+// 适用于支持 llms.WithTools 的模型，例如 deepseekLLM 和 ollamaLLM。
+type FunctionsAgent struct {
+	model llms.ChatModel
+	tools []Tool
+	opts  options
+}
+
+var _ Agent = (*FunctionsAgent)(nil)
+
+// NewFunctionsAgent 创建一个 FunctionsAgent。llm 必须实现 llms.ChatModel，否则返回 ErrToolsNotSupported。
+func NewFunctionsAgent(llm llms.LLM, tools []Tool, opts ...Option) (*FunctionsAgent, error) {
+	model, ok := llm.(llms.ChatModel)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not implement llms.ChatModel", ErrToolsNotSupported, llm)
+	}
+	o := newOptions(opts)
+	if o.systemMessage == "" {
+		o.systemMessage = DefaultFunctionsSystemMessage
+	}
+	return &FunctionsAgent{model: model, tools: tools, opts: o}, nil
+}
+
+func (a *FunctionsAgent) Plan(ctx context.Context, steps []AgentStep, inputs map[string]any, opts ...llms.CallOption) ([]AgentAction, *AgentFinish, error) {
+	messages := []llms.Message{
+		llms.SystemMessage(a.opts.systemMessage),
+		llms.UserMessage(fmt.Sprint(inputs[DefaultInputKey])),
+	}
+	messages = append(messages, a.stepMessages(steps)...)
+
+	callOpts := append(append([]llms.CallOption{llms.WithTools(a.llmTools()...)}, a.opts.callOptions...), opts...)
+	resp, err := a.model.GenerateContent(ctx, messages, callOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(resp.ToolCalls) == 0 {
+		return nil, &AgentFinish{
+			ReturnValues: map[string]any{a.opts.outputKey: resp.Content},
+			Log:          resp.Content,
+		}, nil
+	}
+
+	actions := make([]AgentAction, 0, len(resp.ToolCalls))
+	for _, call := range resp.ToolCalls {
+		if call.FunctionCall == nil {
+			continue
+		}
+		actions = append(actions, AgentAction{
+			Tool:          call.FunctionCall.Name,
+			ToolInput:     a.toolInput(call.FunctionCall),
+			Log:           resp.Content,
+			ToolCallID:    call.ID,
+			ToolCallIndex: len(actions),
+		})
+	}
+	return actions, nil, nil
+}
+
+// stepMessages 把执行过的步骤放回对话历史：同一次响应中的工具调用合并为一条助手消息，
+// 后面依次跟着每个调用的工具结果，与模型返回时的形状保持一致。
+func (a *FunctionsAgent) stepMessages(steps []AgentStep) []llms.Message {
+	var messages []llms.Message
+	for start := 0; start < len(steps); {
+		end := start + 1
+		for end < len(steps) && steps[end].Action.ToolCallIndex > 0 {
+			end++
+		}
+		group := steps[start:end]
+
+		calls := make([]llms.ToolCall, len(group))
+		for i, s := range group {
+			calls[i] = llms.ToolCall{
+				ID:           s.Action.ToolCallID,
+				Type:         llms.ToolTypeFunction,
+				FunctionCall: &llms.FunctionCall{Name: s.Action.Tool, Arguments: a.toolArguments(s.Action)},
+			}
+		}
+		messages = append(messages, llms.Message{Role: llms.RoleAssistant, Content: group[0].Action.Log, ToolCalls: calls})
+		for _, s := range group {
+			messages = append(messages, llms.ToolMessage(s.Action.ToolCallID, s.Action.Tool, s.Observation))
+		}
+		start = end
+	}
+	return messages
+}
+
+func (a *FunctionsAgent) GetInputKeys() []string {
+	return []string{DefaultInputKey}
+}
+
+func (a *FunctionsAgent) GetOutputKeys() []string {
+	return []string{a.opts.outputKey}
+}
+
+func (a *FunctionsAgent) GetTools() []Tool {
+	return a.tools
+}
+
+// llmTools 把工具转换为发给模型的工具定义。
+func (a *FunctionsAgent) llmTools() []llms.Tool {
+	tools := make([]llms.Tool, len(a.tools))
+	for i, t := range a.tools {
+		params := any(defaultToolParameters)
+		if st, ok := t.(StructuredTool); ok {
+			params = st.Parameters()
+		}
+		tools[i] = llms.FunctionTool(t.Name(), t.Description(), params)
+	}
+	return tools
+}
+
+// toolInput 把模型生成的参数转换为工具的输入：StructuredTool 收到原始 JSON，
+// 其余工具收到 input 字段的字符串。
+func (a *FunctionsAgent) toolInput(call *llms.FunctionCall) string {
+	if _, ok := a.findTool(call.Name).(StructuredTool); ok {
+		return call.Arguments
+	}
+	var args struct {
+		Input any `json:"input"`
+	}
+	if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil || args.Input == nil {
+		return call.Arguments
+	}
+	if s, ok := args.Input.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(args.Input)
+	return string(b)
+}
+
+// toolArguments 是 toolInput 的逆过程，用于把执行过的调用放回对话历史。
+func (a *FunctionsAgent) toolArguments(action AgentAction) string {
+	if _, ok := a.findTool(action.Tool).(StructuredTool); ok {
+		return action.ToolInput
+	}
+	b, _ := json.Marshal(map[string]string{"input": action.ToolInput})
+	return string(b)
+}
+
+func (a *FunctionsAgent) findTool(name string) Tool {
+	i := slices.IndexFunc(a.tools, func(t Tool) bool { return t.Name() == name })
+	if i < 0 {
+		return nil
+	}
+	return a.tools[i]
+}
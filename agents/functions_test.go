@@ -0,0 +1,102 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// fakeChatModel 按顺序返回预设的响应，并记录每次收到的消息。
+type fakeChatModel struct {
+	responses []*llms.ContentResponse
+	calls     [][]llms.Message
+}
+
+func (m *fakeChatModel) Call(string, ...llms.CallOption) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (m *fakeChatModel) Generate([]string, ...llms.CallOption) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *fakeChatModel) GenerateContent(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.calls = append(m.calls, messages)
+	if len(m.responses) == 0 {
+		return nil, errors.New("no more responses")
+	}
+	resp := m.responses[0]
+	m.responses = m.responses[1:]
+	return resp, nil
+}
+
+type upperTool struct{}
+
+func (upperTool) Name() string        { return "upper" }
+func (upperTool) Description() string { return "upper-cases the input" }
+func (upperTool) Call(ctx context.Context, input string) (string, error) {
+	return strings.ToUpper(input), nil
+}
+
+func toolCall(id, arg string) llms.ToolCall {
+	return llms.ToolCall{
+		ID:           id,
+		Type:         llms.ToolTypeFunction,
+		FunctionCall: &llms.FunctionCall{Name: "upper", Arguments: `{"input":"` + arg + `"}`},
+	}
+}
+
+// TestFunctionsAgentParallelToolCalls 检查同一次响应中的多个工具调用被放回同一条助手消息，
+// 后面依次跟着各自的工具结果，而不同响应的调用保持独立。
+func TestFunctionsAgentParallelToolCalls(t *testing.T) {
+	model := &fakeChatModel{responses: []*llms.ContentResponse{
+		{Content: "checking both", ToolCalls: []llms.ToolCall{toolCall("call_a", "a"), toolCall("call_b", "b")}},
+		{ToolCalls: []llms.ToolCall{toolCall("call_c", "c")}},
+		{Content: "done"},
+	}}
+	agent, err := NewFunctionsAgent(model, []Tool{upperTool{}})
+	if err != nil {
+		t.Fatalf("NewFunctionsAgent: %v", err)
+	}
+	out, err := NewExecutor(agent).Call(context.Background(), map[string]any{DefaultInputKey: "go"})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if out[DefaultOutputKey] != "done" {
+		t.Fatalf("output = %v, want done", out[DefaultOutputKey])
+	}
+	if len(model.calls) != 3 {
+		t.Fatalf("model called %d times, want 3", len(model.calls))
+	}
+
+	// 第三次调用时的历史：系统、用户、(助手 + 2 个工具结果)、(助手 + 1 个工具结果)。
+	history := model.calls[2][2:]
+	type summary struct {
+		Role    llms.Role
+		Content string
+		CallIDs []string
+		ToolID  string
+	}
+	var got []summary
+	for _, m := range history {
+		s := summary{Role: m.Role, Content: m.Content, ToolID: m.ToolCallID}
+		for _, c := range m.ToolCalls {
+			s.CallIDs = append(s.CallIDs, c.ID)
+		}
+		got = append(got, s)
+	}
+	want := []summary{
+		{Role: llms.RoleAssistant, Content: "checking both", CallIDs: []string{"call_a", "call_b"}},
+		{Role: llms.RoleTool, Content: "A", ToolID: "call_a"},
+		{Role: llms.RoleTool, Content: "B", ToolID: "call_b"},
+		{Role: llms.RoleAssistant, CallIDs: []string{"call_c"}},
+		{Role: llms.RoleTool, Content: "C", ToolID: "call_c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("history =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package agents
+
+import (
+	"context"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/zideajang/langChaingo/chains"
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/prompts"
+)
+
+// DefaultReActPrompt 是 ReActAgent 默认的提示词模板。
+const DefaultReActPrompt = `Answer the following questions as best you can. You have access to the following tools:
+
+{{.tools}}
+
+Use the following format:
+
+Question: the input question you must answer
+Thought: you should always think about what to do
+Action: the action to take, should be one of [{{.tool_names}}]
+Action Input: the input to the action
+Observation: the result of the action
+... (this Thought/Action/Action Input/Observation can repeat N times)
+Thought: I now know the final answer
+Final Answer: the final answer to the original input question
+
+Begin!
+
+Question: {{.input}}
+Thought:{{.agent_scratchpad}}`
+
+const (
+	finalAnswerPrefix = "Final Answer:"
+	observationPrefix = "Observation:"
+)
+
+var actionPattern = regexp.MustCompile(`(?s)Action\s*:\s*(.*?)\s*Action\s*Input\s*:\s*(.*)`)
+
+// ReActAgent 让模型以 Thought/Action/Action Input/Observation 的文本格式推理并选择工具，
+// 适用于任意 llms.LLM，包括不支持原生工具调用的模型。
+type ReActAgent struct {
+	chain *chains.LLMChain
+	tools []Tool
+	opts  options
+}
+
+var _ Agent = (*ReActAgent)(nil)
+
+// NewReActAgent 创建一个 ReActAgent。
+func NewReActAgent(llm llms.LLM, tools []Tool, opts ...Option) *ReActAgent {
+	o := newOptions(opts)
+	if o.prompt == "" {
+		o.prompt = DefaultReActPrompt
+	}
+	prompt := prompts.NewPromptTemplate(o.prompt, nil)
+	// 模板无法解析时留给 Format 报错，这里只需要知道模板引用了哪些变量。
+	prompt.InputVariables, _ = prompt.Variables()
+
+	return &ReActAgent{
+		chain: chains.NewLLMChain(llm, prompt, chains.WithCallOptions(
+			append([]llms.CallOption{llms.WithStopWords("\n" + observationPrefix)}, o.callOptions...)...)),
+		tools: tools,
+		opts:  o,
+	}
+}
+
+func (a *ReActAgent) Plan(ctx context.Context, steps []AgentStep, inputs map[string]any, opts ...llms.CallOption) ([]AgentAction, *AgentFinish, error) {
+	values := make(map[string]any, len(inputs)+3)
+	maps.Copy(values, inputs)
+	values["tools"] = toolDescriptions(a.tools)
+	values["tool_names"] = strings.Join(toolNames(a.tools), ", ")
+	values["agent_scratchpad"] = scratchpad(steps)
+
+	outputs, err := a.chain.Call(ctx, values, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, _ := outputs[chains.DefaultOutputKey].(string)
+	return a.parseOutput(output)
+}
+
+func (a *ReActAgent) GetInputKeys() []string {
+	return []string{DefaultInputKey}
+}
+
+func (a *ReActAgent) GetOutputKeys() []string {
+	return []string{a.opts.outputKey}
+}
+
+func (a *ReActAgent) GetTools() []Tool {
+	return a.tools
+}
+
+// parseOutput 从模型输出中解析出工具调用或最终答案，两者都出现时以先出现的为准。
+func (a *ReActAgent) parseOutput(output string) ([]AgentAction, *AgentFinish, error) {
+	finalIdx := strings.Index(output, finalAnswerPrefix)
+	match := actionPattern.FindStringSubmatchIndex(output)
+
+	if match != nil && (finalIdx < 0 || match[0] < finalIdx) {
+		input := output[match[4]:match[5]]
+		// 模型没有在 Observation 之前停下时，丢弃它自己编造的后续内容。
+		if i := strings.Index(input, observationPrefix); i >= 0 {
+			input = input[:i]
+		}
+		if i := strings.Index(input, finalAnswerPrefix); i >= 0 {
+			input = input[:i]
+		}
+		input = strings.TrimSpace(input)
+		action := AgentAction{
+			Tool:      strings.TrimSpace(output[match[2]:match[3]]),
+			ToolInput: strings.Trim(input, "\"`"),
+			Log:       strings.TrimRight(output[:match[4]]+input, " \n"),
+		}
+		return []AgentAction{action}, nil, nil
+	}
+	if finalIdx >= 0 {
+		answer := strings.TrimSpace(output[finalIdx+len(finalAnswerPrefix):])
+		return nil, &AgentFinish{
+			ReturnValues: map[string]any{a.opts.outputKey: answer},
+			Log:          output,
+		}, nil
+	}
+	return nil, nil, &ParseError{Output: output}
+}
+
+// scratchpad 把已经执行过的步骤按 ReAct 格式拼接起来，作为模型继续推理的上下文。
+func scratchpad(steps []AgentStep) string {
+	var b strings.Builder
+	for _, s := range steps {
+		b.WriteString(s.Action.Log)
+		b.WriteString("\n" + observationPrefix + " " + s.Observation + "\nThought:")
+	}
+	return b.String()
+}
+
+func toolNames(tools []Tool) []string {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Name()
+	}
+	return names
+}
+
+func toolDescriptions(tools []Tool) string {
+	lines := make([]string, len(tools))
+	for i, t := range tools {
+		lines[i] = t.Name() + ": " + t.Description()
+	}
+	return strings.Join(lines, "\n")
+}
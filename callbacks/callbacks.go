@@ -0,0 +1,222 @@
+// Package callbacks 用于观察模型、链、工具和 agent 的执行过程，例如记录提示词、耗时和错误。
+//
+// Handler 可以在创建模型时通过 WithCallbacks 注册到模型实例上，也可以通过 WithHandler
+// 放进 context，只对这一次调用（以及它内部的所有链、工具和模型调用）生效：
+//
+//	ctx = callbacks.WithHandler(ctx, callbacks.NewSlogHandler(slog.Default()))
+//	out, err := chains.Run(ctx, chain, "你好")
+//
+// 只观察某一次模型调用时，可以使用 WithCallHandler 作为调用选项：
+//
+//	resp, err := llm.GenerateContent(ctx, messages, callbacks.WithCallHandler(h))
+package callbacks
+
+import (
+	"context"
+	"slices"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// Handler 接收执行过程中的事件。Handler 的方法在调用方的 goroutine 中同步执行，
+// 批量生成时可能被并发调用，实现需要保证并发安全并尽快返回。
+type Handler interface {
+	// OnLLMStart 在向模型发送请求之前调用。
+	OnLLMStart(ctx context.Context, messages []llms.Message)
+	// OnLLMNewToken 在流式输出收到新内容时调用，只有设置了 llms.WithStreamingFunc 时才会触发。
+	OnLLMNewToken(ctx context.Context, chunk string)
+	// OnLLMEnd 在模型返回完整响应后调用。
+	OnLLMEnd(ctx context.Context, resp *llms.ContentResponse)
+	// OnLLMError 在模型调用失败时调用。
+	OnLLMError(ctx context.Context, err error)
+
+	// OnChainStart 在链开始执行时调用，chain 是链的类型名称。
+	OnChainStart(ctx context.Context, chain string, inputs map[string]any)
+	// OnChainEnd 在链执行成功后调用。
+	OnChainEnd(ctx context.Context, chain string, outputs map[string]any)
+	// OnChainError 在链执行失败时调用。
+	OnChainError(ctx context.Context, chain string, err error)
+
+	// OnToolStart 在 agent 执行工具之前调用。
+	OnToolStart(ctx context.Context, tool, input string)
+	// OnToolEnd 在工具执行成功后调用。
+	OnToolEnd(ctx context.Context, tool, output string)
+	// OnToolError 在工具执行失败时调用。
+	OnToolError(ctx context.Context, tool string, err error)
+
+	// OnAgentAction 在 agent 决定调用工具时调用。
+	OnAgentAction(ctx context.Context, tool, toolInput, log string)
+	// OnAgentFinish 在 agent 给出最终答案时调用。
+	OnAgentFinish(ctx context.Context, returnValues map[string]any, log string)
+}
+
+// SimpleHandler 是所有方法都为空的 Handler，可以嵌入到只关心部分事件的 Handler 中。
+type SimpleHandler struct{}
+
+var _ Handler = SimpleHandler{}
+
+func (SimpleHandler) OnLLMStart(context.Context, []llms.Message)            {}
+func (SimpleHandler) OnLLMNewToken(context.Context, string)                 {}
+func (SimpleHandler) OnLLMEnd(context.Context, *llms.ContentResponse)       {}
+func (SimpleHandler) OnLLMError(context.Context, error)                     {}
+func (SimpleHandler) OnChainStart(context.Context, string, map[string]any)  {}
+func (SimpleHandler) OnChainEnd(context.Context, string, map[string]any)    {}
+func (SimpleHandler) OnChainError(context.Context, string, error)           {}
+func (SimpleHandler) OnToolStart(context.Context, string, string)           {}
+func (SimpleHandler) OnToolEnd(context.Context, string, string)             {}
+func (SimpleHandler) OnToolError(context.Context, string, error)            {}
+func (SimpleHandler) OnAgentAction(context.Context, string, string, string) {}
+func (SimpleHandler) OnAgentFinish(context.Context, map[string]any, string) {}
+
+// MultiHandler 把事件依次转发给多个 Handler。
+type MultiHandler []Handler
+
+var _ Handler = MultiHandler(nil)
+
+// Combine 合并多个 Handler，忽略其中的 nil。没有可用的 Handler 时返回 nil。
+func Combine(handlers ...Handler) Handler {
+	var hs MultiHandler
+	for _, h := range handlers {
+		switch h := h.(type) {
+		case nil:
+		case MultiHandler:
+			hs = append(hs, h...)
+		default:
+			hs = append(hs, h)
+		}
+	}
+	switch len(hs) {
+	case 0:
+		return nil
+	case 1:
+		return hs[0]
+	}
+	return hs
+}
+
+func (m MultiHandler) OnLLMStart(ctx context.Context, messages []llms.Message) {
+	for _, h := range m {
+		h.OnLLMStart(ctx, messages)
+	}
+}
+
+func (m MultiHandler) OnLLMNewToken(ctx context.Context, chunk string) {
+	for _, h := range m {
+		h.OnLLMNewToken(ctx, chunk)
+	}
+}
+
+func (m MultiHandler) OnLLMEnd(ctx context.Context, resp *llms.ContentResponse) {
+	for _, h := range m {
+		h.OnLLMEnd(ctx, resp)
+	}
+}
+
+func (m MultiHandler) OnLLMError(ctx context.Context, err error) {
+	for _, h := range m {
+		h.OnLLMError(ctx, err)
+	}
+}
+
+func (m MultiHandler) OnChainStart(ctx context.Context, chain string, inputs map[string]any) {
+	for _, h := range m {
+		h.OnChainStart(ctx, chain, inputs)
+	}
+}
+
+func (m MultiHandler) OnChainEnd(ctx context.Context, chain string, outputs map[string]any) {
+	for _, h := range m {
+		h.OnChainEnd(ctx, chain, outputs)
+	}
+}
+
+func (m MultiHandler) OnChainError(ctx context.Context, chain string, err error) {
+	for _, h := range m {
+		h.OnChainError(ctx, chain, err)
+	}
+}
+
+func (m MultiHandler) OnToolStart(ctx context.Context, tool, input string) {
+	for _, h := range m {
+		h.OnToolStart(ctx, tool, input)
+	}
+}
+
+func (m MultiHandler) OnToolEnd(ctx context.Context, tool, output string) {
+	for _, h := range m {
+		h.OnToolEnd(ctx, tool, output)
+	}
+}
+
+func (m MultiHandler) OnToolError(ctx context.Context, tool string, err error) {
+	for _, h := range m {
+		h.OnToolError(ctx, tool, err)
+	}
+}
+
+func (m MultiHandler) OnAgentAction(ctx context.Context, tool, toolInput, log string) {
+	for _, h := range m {
+		h.OnAgentAction(ctx, tool, toolInput, log)
+	}
+}
+
+func (m MultiHandler) OnAgentFinish(ctx context.Context, returnValues map[string]any, log string) {
+	for _, h := range m {
+		h.OnAgentFinish(ctx, returnValues, log)
+	}
+}
+
+type contextKey struct{}
+
+// WithHandler 返回带有 h 的 context。ctx 中已经有 Handler 时，两者都会收到事件。
+func WithHandler(ctx context.Context, h Handler) context.Context {
+	return context.WithValue(ctx, contextKey{}, Combine(FromContext(ctx), h))
+}
+
+// FromContext 返回 ctx 中的 Handler，没有时返回 nil。
+func FromContext(ctx context.Context) Handler {
+	h, _ := ctx.Value(contextKey{}).(Handler)
+	return h
+}
+
+// WithCallHandler 返回只对本次模型调用生效的调用选项，h 与模型实例和 ctx 中的 Handler 都会收到事件。
+// 多次使用时所有的 Handler 都会收到事件。
+func WithCallHandler(h Handler) llms.CallOption {
+	return func(o *llms.CallOptions) {
+		prev, _ := o.CallbackHandler.(Handler)
+		o.CallbackHandler = Combine(prev, h)
+	}
+}
+
+// RunLLM 供模型实现使用：在 generate 前后向 instance（模型实例上注册的 Handler）、
+// ctx 中的 Handler 和 WithCallHandler 设置的 Handler 发送 LLM 事件，
+// 并把流式输出转发给 OnLLMNewToken。没有任何 Handler 时直接调用 generate。
+func RunLLM(
+	ctx context.Context,
+	instance Handler,
+	messages []llms.Message,
+	opts []llms.CallOption,
+	generate func(opts []llms.CallOption) (*llms.ContentResponse, error),
+) (*llms.ContentResponse, error) {
+	callOpts := llms.NewCallOptions(opts...)
+	call, _ := callOpts.CallbackHandler.(Handler)
+	h := Combine(instance, FromContext(ctx), call)
+	if h == nil {
+		return generate(opts)
+	}
+
+	h.OnLLMStart(ctx, messages)
+	if streamingFunc := callOpts.StreamingFunc; streamingFunc != nil {
+		opts = append(slices.Clone(opts), llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			h.OnLLMNewToken(ctx, string(chunk))
+			return streamingFunc(ctx, chunk)
+		}))
+	}
+	resp, err := generate(opts)
+	if err != nil {
+		h.OnLLMError(ctx, err)
+		return nil, err
+	}
+	h.OnLLMEnd(ctx, resp)
+	return resp, nil
+}
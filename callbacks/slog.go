@@ -0,0 +1,132 @@
+package callbacks
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// SlogHandler 使用 log/slog 输出结构化日志。开始和结束事件使用 Info 级别，
+// 错误使用 Error 级别，流式输出的每个片段使用 Debug 级别。
+// 默认只记录消息数量和长度，提示词、输出等内容需要通过 WithLogContent 开启。
+type SlogHandler struct {
+	logger     *slog.Logger
+	logContent bool
+}
+
+var _ Handler = (*SlogHandler)(nil)
+
+// SlogOption 是用于配置 SlogHandler 的函数选项。
+type SlogOption func(*SlogHandler)
+
+// WithLogContent 设置在日志中记录提示词、模型输出、链的输入输出以及工具的输入输出。
+// 这些内容可能包含敏感信息，默认关闭。
+func WithLogContent() SlogOption {
+	return func(h *SlogHandler) {
+		h.logContent = true
+	}
+}
+
+// NewSlogHandler 创建一个使用 logger 输出日志的 Handler，logger 为 nil 时使用 slog.Default()。
+func NewSlogHandler(logger *slog.Logger, opts ...SlogOption) *SlogHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	h := &SlogHandler{logger: logger}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *SlogHandler) OnLLMStart(ctx context.Context, messages []llms.Message) {
+	attrs := []any{slog.Int("messages", len(messages)), slog.Int("estimated_tokens", llms.EstimateMessagesTokens(messages))}
+	if h.logContent {
+		attrs = append(attrs, slog.Any("prompt", messages))
+	}
+	h.logger.InfoContext(ctx, "llm start", attrs...)
+}
+
+func (h *SlogHandler) OnLLMNewToken(ctx context.Context, chunk string) {
+	if h.logContent {
+		h.logger.DebugContext(ctx, "llm token", slog.String("chunk", chunk))
+		return
+	}
+	h.logger.DebugContext(ctx, "llm token", slog.Int("bytes", len(chunk)))
+}
+
+func (h *SlogHandler) OnLLMEnd(ctx context.Context, resp *llms.ContentResponse) {
+	attrs := []any{
+		slog.String("model", resp.Model),
+		slog.String("finish_reason", resp.FinishReason),
+		slog.Duration("latency", resp.Timings.Latency),
+		slog.Int("prompt_tokens", resp.Usage.PromptTokens),
+		slog.Int("completion_tokens", resp.Usage.CompletionTokens),
+		slog.Int("tool_calls", len(resp.ToolCalls)),
+	}
+	if h.logContent {
+		attrs = append(attrs, slog.String("content", resp.Content))
+	}
+	h.logger.InfoContext(ctx, "llm end", attrs...)
+}
+
+func (h *SlogHandler) OnLLMError(ctx context.Context, err error) {
+	h.logger.ErrorContext(ctx, "llm error", slog.Any("error", err))
+}
+
+func (h *SlogHandler) OnChainStart(ctx context.Context, chain string, inputs map[string]any) {
+	attrs := []any{slog.String("chain", chain)}
+	if h.logContent {
+		attrs = append(attrs, slog.Any("inputs", inputs))
+	}
+	h.logger.InfoContext(ctx, "chain start", attrs...)
+}
+
+func (h *SlogHandler) OnChainEnd(ctx context.Context, chain string, outputs map[string]any) {
+	attrs := []any{slog.String("chain", chain)}
+	if h.logContent {
+		attrs = append(attrs, slog.Any("outputs", outputs))
+	}
+	h.logger.InfoContext(ctx, "chain end", attrs...)
+}
+
+func (h *SlogHandler) OnChainError(ctx context.Context, chain string, err error) {
+	h.logger.ErrorContext(ctx, "chain error", slog.String("chain", chain), slog.Any("error", err))
+}
+
+func (h *SlogHandler) OnToolStart(ctx context.Context, tool, input string) {
+	attrs := []any{slog.String("tool", tool)}
+	if h.logContent {
+		attrs = append(attrs, slog.String("input", input))
+	}
+	h.logger.InfoContext(ctx, "tool start", attrs...)
+}
+
+func (h *SlogHandler) OnToolEnd(ctx context.Context, tool, output string) {
+	attrs := []any{slog.String("tool", tool), slog.Int("output_bytes", len(output))}
+	if h.logContent {
+		attrs = append(attrs, slog.String("output", output))
+	}
+	h.logger.InfoContext(ctx, "tool end", attrs...)
+}
+
+func (h *SlogHandler) OnToolError(ctx context.Context, tool string, err error) {
+	h.logger.ErrorContext(ctx, "tool error", slog.String("tool", tool), slog.Any("error", err))
+}
+
+func (h *SlogHandler) OnAgentAction(ctx context.Context, tool, toolInput, log string) {
+	attrs := []any{slog.String("tool", tool)}
+	if h.logContent {
+		attrs = append(attrs, slog.String("tool_input", toolInput), slog.String("log", log))
+	}
+	h.logger.InfoContext(ctx, "agent action", attrs...)
+}
+
+func (h *SlogHandler) OnAgentFinish(ctx context.Context, returnValues map[string]any, log string) {
+	var attrs []any
+	if h.logContent {
+		attrs = append(attrs, slog.Any("return_values", returnValues), slog.String("log", log))
+	}
+	h.logger.InfoContext(ctx, "agent finish", attrs...)
+}
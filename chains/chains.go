@@ -0,0 +1,105 @@
+// Package chains 把提示词模板、模型和输出解析器组合成可以复用、可以串联的调用步骤。
+//
+//	prompt := prompts.NewPromptTemplate("用一句话介绍{{.topic}}", []string{"topic"})
+//	chain := chains.NewLLMChain(llm, prompt)
+//	out, err := chains.Run(ctx, chain, "Go 语言")
+package chains
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zideajang/langChaingo/callbacks"
+	"github.com/zideajang/langChaingo/llms"
+)
+
+var (
+	// ErrMissingInputValues 表示调用链时缺少链需要的输入。
+	ErrMissingInputValues = errors.New("chains: missing input values")
+	// ErrMissingOutputValues 表示链没有返回它声明的输出。
+	ErrMissingOutputValues = errors.New("chains: missing output values")
+	// ErrInvalidSequence 表示串联的链之间输入输出对不上。
+	ErrInvalidSequence = errors.New("chains: invalid chain sequence")
+	// ErrRunUnsupported 表示链不是单输入单输出，不能使用 Run。
+	ErrRunUnsupported = errors.New("chains: Run requires exactly one input key and one output key")
+	// ErrOutputNotString 表示 Run 得到的输出不是字符串。
+	ErrOutputNotString = errors.New("chains: output is not a string")
+)
+
+// Chain 是可以被调用、可以被串联的一个步骤。
+type Chain interface {
+	// Call 使用 inputs 执行链，返回以输出键为键的结果。
+	// opts 会传给链中所有的模型调用。
+	Call(ctx context.Context, inputs map[string]any, opts ...llms.CallOption) (map[string]any, error)
+	// GetInputKeys 返回链需要的输入键。
+	GetInputKeys() []string
+	// GetOutputKeys 返回链产生的输出键。
+	GetOutputKeys() []string
+}
+
+// Call 在调用链之前检查 inputs 包含所有输入键，调用之后检查结果包含所有输出键。
+// ctx 中有 callbacks.Handler 时会发送链的开始、结束和错误事件。
+// 推荐使用 Call 而不是直接调用 Chain.Call。
+func Call(ctx context.Context, c Chain, inputs map[string]any, opts ...llms.CallOption) (map[string]any, error) {
+	h := callbacks.FromContext(ctx)
+	name := fmt.Sprintf("%T", c)
+	if h != nil {
+		h.OnChainStart(ctx, name, inputs)
+	}
+	outputs, err := call(ctx, c, inputs, opts)
+	if h != nil {
+		if err != nil {
+			h.OnChainError(ctx, name, err)
+		} else {
+			h.OnChainEnd(ctx, name, outputs)
+		}
+	}
+	return outputs, err
+}
+
+func call(ctx context.Context, c Chain, inputs map[string]any, opts []llms.CallOption) (map[string]any, error) {
+	if missing := missingKeys(c.GetInputKeys(), inputs); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingInputValues, strings.Join(missing, ", "))
+	}
+	outputs, err := c.Call(ctx, inputs, opts...)
+	if err != nil {
+		// 部分链（例如记忆更新失败的 ConversationChain）会同时返回有效的输出和错误。
+		return outputs, err
+	}
+	if missing := missingKeys(c.GetOutputKeys(), outputs); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingOutputValues, strings.Join(missing, ", "))
+	}
+	return outputs, nil
+}
+
+// Run 调用单输入单输出的链，把 input 作为唯一的输入，并返回字符串形式的输出。
+func Run(ctx context.Context, c Chain, input any, opts ...llms.CallOption) (string, error) {
+	inputKeys, outputKeys := c.GetInputKeys(), c.GetOutputKeys()
+	if len(inputKeys) != 1 || len(outputKeys) != 1 {
+		return "", ErrRunUnsupported
+	}
+	outputs, err := Call(ctx, c, map[string]any{inputKeys[0]: input}, opts...)
+	if err != nil {
+		return "", err
+	}
+	s, ok := outputs[outputKeys[0]].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: got %T", ErrOutputNotString, outputs[outputKeys[0]])
+	}
+	return s, nil
+}
+
+// missingKeys 返回 values 中没有的键，已排序。
+func missingKeys(keys []string, values map[string]any) []string {
+	var missing []string
+	for _, k := range keys {
+		if _, ok := values[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
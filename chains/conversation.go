@@ -0,0 +1,78 @@
+package chains
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/memory"
+	"github.com/zideajang/langChaingo/prompts"
+)
+
+// ConversationInputKey 是 ConversationChain 默认提示词的输入键。
+const ConversationInputKey = "input"
+
+// ConversationChain 在每次调用前从 Memory 读取历史、交给 LLMChain，调用结束后把这一轮对话写回 Memory。
+type ConversationChain struct {
+	LLMChain *LLMChain
+	Memory   memory.Memory
+}
+
+var _ Chain = (*ConversationChain)(nil)
+
+// NewConversationChain 创建一个使用默认提示词的对话链：历史消息之后是一条用户消息 {{.input}}。
+// 默认提示词要求 mem 以 []llms.Message 的形式提供键为 memory.DefaultMemoryKey 的历史。
+// 需要自定义提示词（例如加入系统消息）时，可以直接构造 ConversationChain。
+func NewConversationChain(llm llms.LLM, mem memory.Memory, opts ...LLMChainOption) *ConversationChain {
+	prompt := prompts.NewChatPromptTemplate(
+		prompts.MessagesPlaceholder{VariableName: memory.DefaultMemoryKey},
+		prompts.NewUserMessagePromptTemplate("{{."+ConversationInputKey+"}}", []string{ConversationInputKey}),
+	)
+	return &ConversationChain{
+		LLMChain: NewLLMChain(llm, prompt, opts...),
+		Memory:   mem,
+	}
+}
+
+// Call 加载对话记忆后调用模型，再把这一轮对话写回记忆。
+// 记忆更新摘要失败（memory.ErrSummarizeFailed）时，这一轮对话已经保存，
+// Call 和 chains.Call 会同时返回模型的输出和该错误。
+func (c *ConversationChain) Call(ctx context.Context, inputs map[string]any, opts ...llms.CallOption) (map[string]any, error) {
+	history, err := c.Memory.LoadMemoryVariables(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]any, len(inputs)+len(history))
+	maps.Copy(values, history)
+	maps.Copy(values, inputs)
+
+	outputs, resp, err := c.LLMChain.call(ctx, values, opts)
+	if err != nil {
+		return nil, err
+	}
+	// 把这次调用的 token 用量一起交给 Memory，写入会话历史；返回给调用方的输出不包含用量。
+	saved := maps.Clone(outputs)
+	saved[memory.UsageKey] = resp.Usage
+	if err := c.Memory.SaveContext(ctx, inputs, saved); err != nil {
+		// 摘要更新失败时这一轮对话已经保存，回复仍然有效，与错误一起返回。
+		if errors.Is(err, memory.ErrSummarizeFailed) {
+			return outputs, err
+		}
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// GetInputKeys 返回提示词需要的变量，由 Memory 提供的变量除外。
+func (c *ConversationChain) GetInputKeys() []string {
+	memoryKeys := c.Memory.MemoryVariables()
+	return slices.DeleteFunc(slices.Clone(c.LLMChain.GetInputKeys()), func(k string) bool {
+		return slices.Contains(memoryKeys, k)
+	})
+}
+
+func (c *ConversationChain) GetOutputKeys() []string {
+	return c.LLMChain.GetOutputKeys()
+}
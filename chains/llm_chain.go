@@ -0,0 +1,152 @@
+package chains
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/outputparser"
+	"github.com/zideajang/langChaingo/prompts"
+)
+
+const (
+	// DefaultOutputKey 是 LLMChain 默认的输出键。
+	DefaultOutputKey = "text"
+	// FormatInstructionsKey 是提示词模板中用于插入输出格式说明的变量名。
+	// 模板声明了这个变量而调用方没有提供时，LLMChain 会使用输出解析器的格式说明。
+	FormatInstructionsKey = "format_instructions"
+)
+
+// LLMChain 使用输入渲染提示词模板，调用模型，再用输出解析器解析模型的输出。
+type LLMChain struct {
+	Prompt       prompts.FormatPrompter
+	LLM          llms.LLM
+	OutputParser outputparser.OutputParser
+	OutputKey    string
+	// CallOptions 是每次调用模型时都会带上的选项，调用 Call 时传入的选项优先级更高。
+	CallOptions []llms.CallOption
+}
+
+var _ Chain = (*LLMChain)(nil)
+
+// LLMChainOption 是用于配置 LLMChain 的函数选项。
+type LLMChainOption func(*LLMChain)
+
+// WithOutputParser 设置输出解析器，默认使用 outputparser.Simple。
+func WithOutputParser(parser outputparser.OutputParser) LLMChainOption {
+	return func(c *LLMChain) {
+		c.OutputParser = parser
+	}
+}
+
+// WithOutputKey 设置输出键，默认为 DefaultOutputKey。
+func WithOutputKey(key string) LLMChainOption {
+	return func(c *LLMChain) {
+		c.OutputKey = key
+	}
+}
+
+// WithCallOptions 设置每次调用模型时都会带上的选项，例如温度、最大 token 数等。
+func WithCallOptions(opts ...llms.CallOption) LLMChainOption {
+	return func(c *LLMChain) {
+		c.CallOptions = append(c.CallOptions, opts...)
+	}
+}
+
+// NewLLMChain 创建一个 LLMChain。llm 可以是任意 llms.LLM，
+// 实现了 llms.ChatModel 的模型（例如 ollamaLLM、deepseekLLM）会收到带角色的消息列表。
+//
+// NewLLMChain 不校验提示词模板，模板的错误要到调用时才会由 Format 报告；
+// 为了保持签名不变，需要在创建时发现错误的调用方请使用 NewLLMChainE。
+func NewLLMChain(llm llms.LLM, prompt prompts.FormatPrompter, opts ...LLMChainOption) *LLMChain {
+	c := &LLMChain{
+		Prompt:       prompt,
+		LLM:          llm,
+		OutputParser: outputparser.NewSimple(),
+		OutputKey:    DefaultOutputKey,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewLLMChainE 与 NewLLMChain 相同，但提示词模板实现了 Validate() error 时
+// （例如 prompts.PromptTemplate、prompts.ChatPromptTemplate）会先校验模板，
+// 模板无法解析或者引用的变量与声明的不一致时返回错误。
+func NewLLMChainE(llm llms.LLM, prompt prompts.FormatPrompter, opts ...LLMChainOption) (*LLMChain, error) {
+	if v, ok := prompt.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("chains: invalid prompt template: %w", err)
+		}
+	}
+	return NewLLMChain(llm, prompt, opts...), nil
+}
+
+func (c *LLMChain) Call(ctx context.Context, inputs map[string]any, opts ...llms.CallOption) (map[string]any, error) {
+	outputs, _, err := c.call(ctx, inputs, opts)
+	return outputs, err
+}
+
+// call 执行链并同时返回模型的原始响应，供需要 token 用量等信息的链使用。
+func (c *LLMChain) call(ctx context.Context, inputs map[string]any, opts []llms.CallOption) (map[string]any, *llms.ContentResponse, error) {
+	if instructions := c.OutputParser.GetFormatInstructions(); instructions != "" {
+		if _, ok := inputs[FormatInstructionsKey]; !ok && slices.Contains(c.Prompt.GetInputVariables(), FormatInstructionsKey) {
+			inputs = maps.Clone(inputs)
+			inputs[FormatInstructionsKey] = instructions
+		}
+	}
+
+	value, err := c.Prompt.FormatPrompt(inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	callOpts := append(append([]llms.CallOption{}, c.CallOptions...), opts...)
+	resp, err := generate(ctx, c.LLM, value, callOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := c.OutputParser.Parse(resp.Content)
+	if err != nil {
+		return nil, nil, err
+	}
+	return map[string]any{c.OutputKey: output}, resp, nil
+}
+
+// GetInputKeys 返回提示词模板需要的变量，输出解析器能提供的格式说明除外。
+func (c *LLMChain) GetInputKeys() []string {
+	keys := c.Prompt.GetInputVariables()
+	if c.OutputParser.GetFormatInstructions() == "" {
+		return keys
+	}
+	return slices.DeleteFunc(slices.Clone(keys), func(k string) bool {
+		return k == FormatInstructionsKey
+	})
+}
+
+func (c *LLMChain) GetOutputKeys() []string {
+	return []string{c.OutputKey}
+}
+
+// generate 按模型支持的能力选择调用方式：优先发送消息列表，其次使用带 context 的调用。
+// 只支持文本调用的模型返回的响应中只有 Content。
+func generate(ctx context.Context, llm llms.LLM, value prompts.PromptValue, opts []llms.CallOption) (*llms.ContentResponse, error) {
+	var (
+		text string
+		err  error
+	)
+	switch m := llm.(type) {
+	case llms.ChatModel:
+		return m.GenerateContent(ctx, value.Messages(), opts...)
+	case llms.ContextLLM:
+		text, err = m.CallContext(ctx, value.String(), opts...)
+	default:
+		text, err = llm.Call(value.String(), opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &llms.ContentResponse{Content: text}, nil
+}
@@ -0,0 +1,66 @@
+package chains
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zideajang/langChaingo/prompts"
+)
+
+// TestNewLLMChainE 检查创建链时会校验实现了 Validate 的提示词模板。
+func TestNewLLMChainE(t *testing.T) {
+	tests := []struct {
+		name           string
+		prompt         prompts.FormatPrompter
+		wantErr        bool
+		wantValidation bool // 错误应当是 *prompts.ValidationError
+	}{
+		{
+			name:   "valid template",
+			prompt: prompts.NewPromptTemplate("介绍{{.topic}}", []string{"topic"}),
+		},
+		{
+			name:           "undeclared variable",
+			prompt:         prompts.NewPromptTemplate("介绍{{.topic}}", nil),
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:           "unused variable",
+			prompt:         prompts.NewFStringPromptTemplate("介绍{topic}", []string{"topic", "style"}),
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:    "unparsable template",
+			prompt:  prompts.NewPromptTemplate("介绍{{.topic", []string{"topic"}),
+			wantErr: true,
+		},
+		{
+			name: "chat template",
+			prompt: prompts.NewChatPromptTemplate(
+				prompts.NewSystemMessagePromptTemplate("你是{{.role}}", nil),
+			),
+			wantErr:        true,
+			wantValidation: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := NewLLMChainE(nil, tt.prompt)
+			if !tt.wantErr {
+				if err != nil || chain == nil {
+					t.Fatalf("NewLLMChainE = %v, %v", chain, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("NewLLMChainE returned no error")
+			}
+			var verr *prompts.ValidationError
+			if tt.wantValidation && !errors.As(err, &verr) {
+				t.Fatalf("error = %v, want *prompts.ValidationError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package chains
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// SequentialChain 依次执行多个链，每个链可以使用初始输入以及之前所有链的输出。
+type SequentialChain struct {
+	chains     []Chain
+	inputKeys  []string
+	outputKeys []string
+}
+
+var _ Chain = (*SequentialChain)(nil)
+
+// NewSequentialChain 创建一个 SequentialChain。inputKeys 是整个序列需要的输入，
+// outputKeys 是最终返回的输出，可以是序列中任意一个链的输出键。
+// 创建时会检查每个链需要的输入都能由之前的步骤提供，否则返回 ErrInvalidSequence。
+func NewSequentialChain(chains []Chain, inputKeys, outputKeys []string) (*SequentialChain, error) {
+	if len(chains) == 0 {
+		return nil, fmt.Errorf("%w: no chains", ErrInvalidSequence)
+	}
+	known := map[string]bool{}
+	for _, k := range inputKeys {
+		known[k] = true
+	}
+	for i, c := range chains {
+		for _, k := range c.GetInputKeys() {
+			if !known[k] {
+				return nil, fmt.Errorf("%w: chain %d requires %q which is not provided by the inputs or earlier chains", ErrInvalidSequence, i, k)
+			}
+		}
+		for _, k := range c.GetOutputKeys() {
+			if known[k] {
+				return nil, fmt.Errorf("%w: chain %d output %q overwrites an existing key", ErrInvalidSequence, i, k)
+			}
+			known[k] = true
+		}
+	}
+	for _, k := range outputKeys {
+		if !known[k] {
+			return nil, fmt.Errorf("%w: output %q is not produced by any chain", ErrInvalidSequence, k)
+		}
+	}
+	return &SequentialChain{chains: chains, inputKeys: inputKeys, outputKeys: outputKeys}, nil
+}
+
+func (s *SequentialChain) Call(ctx context.Context, inputs map[string]any, opts ...llms.CallOption) (map[string]any, error) {
+	known := make(map[string]any, len(inputs))
+	maps.Copy(known, inputs)
+	for i, c := range s.chains {
+		outputs, err := Call(ctx, c, known, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("chains: step %d: %w", i, err)
+		}
+		maps.Copy(known, outputs)
+	}
+
+	result := make(map[string]any, len(s.outputKeys))
+	for _, k := range s.outputKeys {
+		result[k] = known[k]
+	}
+	return result, nil
+}
+
+func (s *SequentialChain) GetInputKeys() []string {
+	return slices.Clone(s.inputKeys)
+}
+
+func (s *SequentialChain) GetOutputKeys() []string {
+	return slices.Clone(s.outputKeys)
+}
+
+const (
+	// SimpleSequentialInputKey 是 SimpleSequentialChain 的输入键。
+	SimpleSequentialInputKey = "input"
+	// SimpleSequentialOutputKey 是 SimpleSequentialChain 的输出键。
+	SimpleSequentialOutputKey = "output"
+)
+
+// SimpleSequentialChain 依次执行多个单输入单输出的链，前一个链的输出作为后一个链的输入。
+type SimpleSequentialChain struct {
+	chains []Chain
+}
+
+var _ Chain = (*SimpleSequentialChain)(nil)
+
+// NewSimpleSequentialChain 创建一个 SimpleSequentialChain。
+// 每个链都必须只有一个输入键和一个输出键，否则返回 ErrInvalidSequence。
+func NewSimpleSequentialChain(chains ...Chain) (*SimpleSequentialChain, error) {
+	if len(chains) == 0 {
+		return nil, fmt.Errorf("%w: no chains", ErrInvalidSequence)
+	}
+	for i, c := range chains {
+		if len(c.GetInputKeys()) != 1 || len(c.GetOutputKeys()) != 1 {
+			return nil, fmt.Errorf("%w: chain %d must have exactly one input key and one output key", ErrInvalidSequence, i)
+		}
+	}
+	return &SimpleSequentialChain{chains: chains}, nil
+}
+
+func (s *SimpleSequentialChain) Call(ctx context.Context, inputs map[string]any, opts ...llms.CallOption) (map[string]any, error) {
+	value := inputs[SimpleSequentialInputKey]
+	for i, c := range s.chains {
+		outputs, err := Call(ctx, c, map[string]any{c.GetInputKeys()[0]: value}, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("chains: step %d: %w", i, err)
+		}
+		value = outputs[c.GetOutputKeys()[0]]
+	}
+	return map[string]any{SimpleSequentialOutputKey: value}, nil
+}
+
+func (s *SimpleSequentialChain) GetInputKeys() []string {
+	return []string{SimpleSequentialInputKey}
+}
+
+func (s *SimpleSequentialChain) GetOutputKeys() []string {
+	return []string{SimpleSequentialOutputKey}
+}
@@ -0,0 +1,111 @@
+// Package embeddings 定义了把文本转换为向量的接口，用于检索增强生成(RAG)等场景。
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultBatchSize 是 EmbedDocuments 每次请求默认包含的文本数量。
+const DefaultBatchSize = 32
+
+// Embedder 把文档和查询转换为向量。
+type Embedder interface {
+	// EmbedDocuments 为一组文档生成向量，返回值与输入一一对应。
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+	// EmbedQuery 为单条查询生成向量。
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}
+
+// EmbedderClient 是能够一次为多段文本生成向量的模型客户端，例如 ollamaLLM.OllamaLLM。
+type EmbedderClient interface {
+	CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderImpl 在 EmbedderClient 的基础上实现 Embedder，负责分批请求和文本预处理。
+type EmbedderImpl struct {
+	client        EmbedderClient
+	batchSize     int
+	stripNewLines bool
+}
+
+var _ Embedder = (*EmbedderImpl)(nil)
+
+// Option 是用于配置 EmbedderImpl 的函数选项。
+type Option func(*EmbedderImpl)
+
+// WithBatchSize 设置 EmbedDocuments 每次请求包含的文本数量。
+func WithBatchSize(batchSize int) Option {
+	return func(e *EmbedderImpl) {
+		e.batchSize = batchSize
+	}
+}
+
+// WithStripNewLines 设置是否在生成向量前把换行替换为空格，默认开启。
+func WithStripNewLines(strip bool) Option {
+	return func(e *EmbedderImpl) {
+		e.stripNewLines = strip
+	}
+}
+
+// NewEmbedder 基于 client 创建一个 Embedder。
+func NewEmbedder(client EmbedderClient, opts ...Option) (*EmbedderImpl, error) {
+	if client == nil {
+		return nil, errors.New("embeddings: client is nil")
+	}
+	e := &EmbedderImpl{
+		client:        client,
+		batchSize:     DefaultBatchSize,
+		stripNewLines: true,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.batchSize <= 0 {
+		return nil, fmt.Errorf("embeddings: invalid batch size %d", e.batchSize)
+	}
+	return e, nil
+}
+
+// EmbedDocuments 按批次为文档生成向量，任意一批失败都会返回错误。
+func (e *EmbedderImpl) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	texts = e.prepare(texts)
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += e.batchSize {
+		end := min(start+e.batchSize, len(texts))
+		batch, err := e.client.CreateEmbedding(ctx, texts[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("embeddings: batch [%d, %d) failed: %w", start, end, err)
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("embeddings: got %d vectors for %d texts", len(batch), end-start)
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// EmbedQuery 为单条查询生成向量。
+func (e *EmbedderImpl) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := e.client.CreateEmbedding(ctx, e.prepare([]string{text}))
+	if err != nil {
+		return nil, fmt.Errorf("embeddings: embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embeddings: got %d vectors for 1 query", len(vectors))
+	}
+	return vectors[0], nil
+}
+
+func (e *EmbedderImpl) prepare(texts []string) []string {
+	if !e.stripNewLines {
+		return texts
+	}
+	out := make([]string, len(texts))
+	for i, t := range texts {
+		out[i] = strings.ReplaceAll(t, "\n", " ")
+	}
+	return out
+}
@@ -0,0 +1,386 @@
+package anthropicLLM
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zideajang/langChaingo/callbacks"
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/anthropic/internal/anthropicclient"
+	"github.com/zideajang/langChaingo/llms/internal/runner"
+)
+
+// AnthropicLLM 结构体封装了 Anthropic 客户端和模型配置。
+type AnthropicLLM struct {
+	client *anthropicclient.Client
+	model  string // 模型名称，例如 claude-3-5-haiku-latest
+
+	// clientOptions 在创建内部客户端时传给 anthropicclient.New
+	clientOptions []anthropicclient.Option
+
+	// runner 负责回调、客户端限流和批量生成
+	runner runner.Runner
+}
+
+var (
+	_ llms.ContextLLM = (*AnthropicLLM)(nil)
+	_ llms.ChatModel  = (*AnthropicLLM)(nil)
+	_ llms.BatchLLM   = (*AnthropicLLM)(nil)
+)
+
+// Option 类型定义了用于配置 AnthropicLLM 实例的函数选项。
+type Option func(*AnthropicLLM)
+
+// New 创建一个 AnthropicLLM 实例。
+// API 密钥依次从 WithToken、ANTHROPIC_API_KEY 环境变量中查找。
+func New(opts ...Option) (*AnthropicLLM, error) {
+	llm := &AnthropicLLM{
+		model:  anthropicclient.DefaultChatModel,
+		runner: runner.New("Anthropic"),
+	}
+	for _, opt := range opts {
+		opt(llm)
+	}
+
+	client, err := anthropicclient.New(llm.clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Anthropic client: %w", err)
+	}
+	llm.client = client
+	return llm, nil
+}
+
+// WithModel 设置模型名称。
+func WithModel(model string) Option {
+	return func(llm *AnthropicLLM) {
+		llm.model = model
+	}
+}
+
+// WithToken 直接指定 Anthropic API 密钥，优先于环境变量。
+func WithToken(token string) Option {
+	return func(llm *AnthropicLLM) {
+		llm.clientOptions = append(llm.clientOptions, anthropicclient.WithToken(token))
+	}
+}
+
+// WithBaseURL 指定服务的基础URL，可用于接入兼容 Anthropic 接口的网关或代理。
+func WithBaseURL(baseURL string) Option {
+	return func(llm *AnthropicLLM) {
+		llm.clientOptions = append(llm.clientOptions, anthropicclient.WithBaseURL(baseURL))
+	}
+}
+
+// WithVersion 设置 anthropic-version 请求头，默认为 2023-06-01。
+func WithVersion(version string) Option {
+	return func(llm *AnthropicLLM) {
+		llm.clientOptions = append(llm.clientOptions, anthropicclient.WithVersion(version))
+	}
+}
+
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义 Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(llm *AnthropicLLM) {
+		llm.clientOptions = append(llm.clientOptions, anthropicclient.WithHTTPClient(httpClient))
+	}
+}
+
+// WithHeader 为每个请求附加一个自定义请求头，例如 anthropic-beta。
+func WithHeader(key, value string) Option {
+	return func(llm *AnthropicLLM) {
+		llm.clientOptions = append(llm.clientOptions, anthropicclient.WithHeader(key, value))
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间。
+func WithTimeout(timeout time.Duration) Option {
+	return func(llm *AnthropicLLM) {
+		llm.clientOptions = append(llm.clientOptions, anthropicclient.WithTimeout(timeout))
+	}
+}
+
+// WithMaxConcurrency 设置 Generate 批量生成时同时进行的最大请求数，默认为 8，小于等于 0 时不限制。
+func WithMaxConcurrency(n int) Option {
+	return func(llm *AnthropicLLM) {
+		llm.runner.MaxConcurrency = n
+	}
+}
+
+// WithRateLimit 开启客户端限流，限制每分钟的请求数和 token 数，传入 0 表示不限制该项。
+// 同一个实例上的所有调用共享这一限额。
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) Option {
+	return func(llm *AnthropicLLM) {
+		llm.runner.SetRateLimit(requestsPerMinute, tokensPerMinute)
+	}
+}
+
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1 表示不重试，默认为 3。
+// 429、529（服务过载）、5xx 以及连接错误会按照带随机抖动的指数退避重试。
+func WithMaxAttempts(attempts int) Option {
+	return func(llm *AnthropicLLM) {
+		llm.clientOptions = append(llm.clientOptions, anthropicclient.WithMaxAttempts(attempts))
+	}
+}
+
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(llm *AnthropicLLM) {
+		llm.clientOptions = append(llm.clientOptions, anthropicclient.WithRetryBackoff(initial, max))
+	}
+}
+
+// WithCallbacks 注册接收这个实例上所有调用事件的 Handler。
+func WithCallbacks(h callbacks.Handler) Option {
+	return func(llm *AnthropicLLM) {
+		llm.runner.Callbacks = h
+	}
+}
+
+// Call 方法实现了 llms.LLM 接口的 Call 方法，是 CallContext 的简单封装。
+func (l *AnthropicLLM) Call(prompt string, opts ...llms.CallOption) (string, error) {
+	return l.CallContext(context.Background(), prompt, opts...)
+}
+
+// CallContext 使用调用方传入的 ctx 向 Anthropic 模型发送单个提示。
+func (l *AnthropicLLM) CallContext(ctx context.Context, prompt string, opts ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, l, prompt, opts...)
+}
+
+// GenerateContent 发送一组多轮对话消息。系统消息会合并到请求顶层的 system 字段，
+// 工具结果会作为 user 消息中的 tool_result 内容块发送。
+//
+// Messages API 没有 JSON 模式，llms.WithJSONMode 和 llms.WithJSONSchema 会被忽略，
+// 需要结构化输出时请使用 structured 包，它会把 schema 写入提示词；seed 和惩罚参数同样会被忽略。
+func (l *AnthropicLLM) GenerateContent(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error) {
+	return l.runner.GenerateContent(ctx, messages, opts, l.generateContent)
+}
+
+func (l *AnthropicLLM) generateContent(ctx context.Context, messages []llms.Message, callOpts *llms.CallOptions) (*llms.ContentResponse, error) {
+	system, clientMessages := toClientMessages(messages)
+	req := &anthropicclient.MessagesRequest{
+		Model:         l.modelFor(callOpts),
+		Messages:      clientMessages,
+		System:        system,
+		StreamingFunc: callOpts.StreamingFunc,
+
+		Temperature:   callOpts.Temperature,
+		TopP:          callOpts.TopP,
+		TopK:          callOpts.TopK,
+		StopSequences: callOpts.StopWords,
+
+		Tools:      toClientTools(callOpts.Tools),
+		ToolChoice: toClientToolChoice(callOpts.ToolChoice),
+	}
+	if callOpts.MaxTokens != nil {
+		req.MaxTokens = *callOpts.MaxTokens
+	}
+
+	start := time.Now()
+	resp, err := l.client.CreateMessage(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic CreateMessage failed: %w", err)
+	}
+
+	usage := llms.Usage{
+		PromptTokens:     resp.Usage.InputTokens + resp.Usage.CacheCreationInputTokens + resp.Usage.CacheReadInputTokens,
+		CompletionTokens: resp.Usage.OutputTokens,
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return &llms.ContentResponse{
+		Content:      resp.Text(),
+		Model:        resp.Model,
+		ID:           resp.ID,
+		FinishReason: finishReason(resp.StopReason),
+		ToolCalls:    fromClientToolCalls(resp.Content),
+		Usage:        usage,
+		// Anthropic 不上报服务端耗时，只记录客户端测量的延迟。
+		Timings: llms.Timings{
+			Latency: time.Since(start),
+		},
+		GenerationInfo: map[string]any{
+			"id":                          resp.ID,
+			"type":                        resp.Type,
+			"stop_reason":                 resp.StopReason,
+			"stop_sequence":               resp.StopSequence,
+			"input_tokens":                resp.Usage.InputTokens,
+			"output_tokens":               resp.Usage.OutputTokens,
+			"cache_creation_input_tokens": resp.Usage.CacheCreationInputTokens,
+			"cache_read_input_tokens":     resp.Usage.CacheReadInputTokens,
+		},
+	}, nil
+}
+
+// Generate 方法实现了 llms.LLM 接口的 Generate 方法，是 GenerateContext 的简单封装。
+func (l *AnthropicLLM) Generate(prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return l.GenerateContext(context.Background(), prompts, opts...)
+}
+
+// GenerateContext 为每一个提示调用 GenerateContent，同时进行的请求数受 WithMaxConcurrency 限制。
+// 部分提示失败时仍然返回其余提示的结果（失败的位置为空字符串），同时返回 *llms.BatchError。
+func (l *AnthropicLLM) GenerateContext(ctx context.Context, prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return llms.BatchCompletions(l.GenerateBatch(ctx, prompts, opts...))
+}
+
+// GenerateBatch 并发地为每一个提示生成结果，并按输入顺序返回每个提示各自的成功或失败。
+func (l *AnthropicLLM) GenerateBatch(ctx context.Context, prompts []string, opts ...llms.CallOption) []llms.BatchResult {
+	return l.runner.GenerateBatch(ctx, prompts, opts, l.GenerateContent)
+}
+
+// modelFor 返回本次调用实际使用的模型名称，调用选项优先于实例配置。
+func (l *AnthropicLLM) modelFor(opts *llms.CallOptions) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return l.model
+}
+
+// finishReason 把 Anthropic 的 stop_reason 统一为 llms 中的结束原因，未知的值原样返回。
+func finishReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence":
+		return llms.FinishReasonStop
+	case "max_tokens":
+		return llms.FinishReasonLength
+	case "tool_use":
+		return llms.FinishReasonToolCalls
+	}
+	return stopReason
+}
+
+// toClientMessages 把与供应商无关的消息转换为 Anthropic 的消息结构，并返回合并后的系统提示。
+// tool 消息转换为 user 消息中的 tool_result 内容块；相邻的同角色消息会合并为一条，
+// 例如同一轮中多个工具的结果。
+func toClientMessages(messages []llms.Message) (string, []anthropicclient.Message) {
+	var (
+		system []string
+		out    []anthropicclient.Message
+	)
+	for _, m := range messages {
+		var (
+			role   string
+			blocks []anthropicclient.ContentBlock
+		)
+		switch m.Role {
+		case llms.RoleSystem:
+			system = append(system, m.Content)
+			continue
+		case llms.RoleTool:
+			role = "user"
+			blocks = append(blocks, anthropicclient.ContentBlock{
+				Type:      anthropicclient.BlockTypeToolResult,
+				ToolUseID: m.ToolCallID,
+				Content:   m.Content,
+			})
+		case llms.RoleAssistant:
+			role = "assistant"
+			if m.Content != "" {
+				blocks = append(blocks, anthropicclient.ContentBlock{Type: anthropicclient.BlockTypeText, Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				if tc.FunctionCall == nil {
+					continue
+				}
+				blocks = append(blocks, anthropicclient.ContentBlock{
+					Type:  anthropicclient.BlockTypeToolUse,
+					ID:    tc.ID,
+					Name:  tc.FunctionCall.Name,
+					Input: toRawInput(tc.FunctionCall.Arguments),
+				})
+			}
+		default:
+			role = "user"
+			blocks = append(blocks, anthropicclient.ContentBlock{Type: anthropicclient.BlockTypeText, Text: m.Content})
+		}
+
+		// Messages API 不接受空的content数组，没有任何内容块的消息直接跳过。
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Content = append(out[n-1].Content, blocks...)
+			continue
+		}
+		out = append(out, anthropicclient.Message{Role: role, Content: blocks})
+	}
+	return strings.Join(system, "\n\n"), out
+}
+
+// toRawInput 把工具调用参数转换为tool_use块的input字段。
+// Anthropic要求input是JSON对象，参数为空时使用空对象，
+// 不是合法JSON对象时包装为{"arguments": "..."}，避免整个请求被拒绝。
+func toRawInput(arguments string) json.RawMessage {
+	trimmed := strings.TrimSpace(arguments)
+	if trimmed == "" {
+		return json.RawMessage("{}")
+	}
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	raw, _ := json.Marshal(map[string]string{"arguments": arguments})
+	return raw
+}
+
+// toClientTools 把与供应商无关的工具定义转换为 Anthropic 的工具结构。
+func toClientTools(tools []llms.Tool) []anthropicclient.Tool {
+	var out []anthropicclient.Tool
+	for _, t := range tools {
+		if t.Function == nil {
+			continue
+		}
+		schema := t.Function.Parameters
+		if schema == nil {
+			// input_schema 是必填字段。
+			schema = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		out = append(out, anthropicclient.Tool{
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	return out
+}
+
+// toClientToolChoice 把 llms 的工具选择转换为 Anthropic 的 tool_choice，
+// "required" 对应 Anthropic 的 "any"。
+func toClientToolChoice(choice any) *anthropicclient.ToolChoice {
+	switch c := choice.(type) {
+	case string:
+		switch c {
+		case llms.ToolChoiceAuto:
+			return &anthropicclient.ToolChoice{Type: "auto"}
+		case llms.ToolChoiceNone:
+			return &anthropicclient.ToolChoice{Type: "none"}
+		case llms.ToolChoiceRequired:
+			return &anthropicclient.ToolChoice{Type: "any"}
+		}
+	case *llms.ToolChoice:
+		if c != nil && c.Function != nil {
+			return &anthropicclient.ToolChoice{Type: "tool", Name: c.Function.Name}
+		}
+	}
+	return nil
+}
+
+// fromClientToolCalls 把响应中的 tool_use 内容块转换为与供应商无关的工具调用。
+func fromClientToolCalls(blocks []anthropicclient.ContentBlock) []llms.ToolCall {
+	var out []llms.ToolCall
+	for _, b := range blocks {
+		if b.Type != anthropicclient.BlockTypeToolUse {
+			continue
+		}
+		out = append(out, llms.ToolCall{
+			ID:   b.ID,
+			Type: llms.ToolTypeFunction,
+			FunctionCall: &llms.FunctionCall{
+				Name:      b.Name,
+				Arguments: string(b.Input),
+			},
+		})
+	}
+	return out
+}
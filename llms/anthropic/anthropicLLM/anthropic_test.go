@@ -0,0 +1,53 @@
+package anthropicLLM
+
+import (
+	"testing"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+func TestToRawInput(t *testing.T) {
+	tests := map[string]string{
+		"":                 `{}`,
+		"  ":               `{}`,
+		`{"city":"Paris"}`: `{"city":"Paris"}`,
+		`["a"]`:            `{"arguments":"[\"a\"]"}`,
+		`not json`:         `{"arguments":"not json"}`,
+	}
+	for in, want := range tests {
+		if got := string(toRawInput(in)); got != want {
+			t.Errorf("toRawInput(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestToClientMessages(t *testing.T) {
+	system, messages := toClientMessages([]llms.Message{
+		llms.SystemMessage("be brief"),
+		llms.UserMessage("weather?"),
+		// 没有内容也没有工具调用的 assistant 消息会被跳过，相邻的 user 消息随之合并。
+		{Role: llms.RoleAssistant},
+		llms.UserMessage("in Paris"),
+		{Role: llms.RoleAssistant, ToolCalls: []llms.ToolCall{{
+			ID: "toolu_1", Type: llms.ToolTypeFunction,
+			FunctionCall: &llms.FunctionCall{Name: "get_weather", Arguments: "not json"},
+		}}},
+		{Role: llms.RoleTool, ToolCallID: "toolu_1", Content: "sunny"},
+	})
+	if system != "be brief" {
+		t.Errorf("system = %q", system)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("messages = %+v", messages)
+	}
+	if messages[0].Role != "user" || len(messages[0].Content) != 2 {
+		t.Errorf("first message = %+v", messages[0])
+	}
+	call := messages[1].Content[0]
+	if call.ID != "toolu_1" || string(call.Input) != `{"arguments":"not json"}` {
+		t.Errorf("tool_use block = %+v", call)
+	}
+	if result := messages[2].Content[0]; messages[2].Role != "user" || result.ToolUseID != "toolu_1" {
+		t.Errorf("tool_result block = %+v", result)
+	}
+}
@@ -0,0 +1,313 @@
+package anthropicclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms/internal/httpretry"
+)
+
+// --- Constants ---
+const (
+	// DefaultChatModel 是Anthropic客户端使用的默认模型。
+	DefaultChatModel = "claude-3-5-haiku-latest"
+	// DefaultBaseURL 是Anthropic服务的默认基础URL。
+	DefaultBaseURL = "https://api.anthropic.com"
+	// DefaultVersion 是通过 anthropic-version 请求头发送的接口版本。
+	DefaultVersion = "2023-06-01"
+	// DefaultMaxTokens 是请求未指定 max_tokens 时使用的值，Messages API 要求必须提供该字段。
+	DefaultMaxTokens = 4096
+	// messagesAPIPath 是Anthropic API中用于生成消息的路由。
+	messagesAPIPath = "/v1/messages"
+	// apiKeyEnvVar 是保存Anthropic API Key的环境变量名称。
+	apiKeyEnvVar = "ANTHROPIC_API_KEY"
+)
+
+// --- Errors ---
+// ErrEmptyResponse 表示Anthropic模型返回的内容为空。
+var ErrEmptyResponse = errors.New("empty response from Anthropic")
+
+// ErrAPIKeyNotFound 表示既没有通过 WithToken 指定API Key，ANTHROPIC_API_KEY 环境变量也为空。
+var ErrAPIKeyNotFound = errors.New("Anthropic API Key not found")
+
+// --- Client Structure ---
+
+// Client 表示与Anthropic Messages API交互的客户端。
+type Client struct {
+	apikey     string           // Anthropic API密钥
+	baseURL    string           // Anthropic服务的基准URL
+	version    string           // anthropic-version 请求头
+	httpClient *http.Client     // 用于发送HTTP请求，默认为http.DefaultClient
+	headers    http.Header      // 附加在每个请求上的自定义请求头
+	timeout    time.Duration    // 单次请求的超时时间，为0时不额外设置超时
+	retry      httpretry.Policy // 重试策略
+}
+
+// Option 是用于配置Client的函数选项。
+type Option func(*Client)
+
+// WithToken 直接指定API密钥，优先于 ANTHROPIC_API_KEY 环境变量。
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.apikey = token
+	}
+}
+
+// WithBaseURL 指定服务的基础URL，可用于接入Anthropic兼容的网关或代理。
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithVersion 设置 anthropic-version 请求头，默认为 DefaultVersion。
+func WithVersion(version string) Option {
+	return func(c *Client) {
+		c.version = version
+	}
+}
+
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader 为每个请求附加一个自定义请求头，例如 anthropic-beta。
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Add(key, value)
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间，流式请求的超时覆盖读取整个流的过程。
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1表示不重试。
+func WithMaxAttempts(attempts int) Option {
+	return func(c *Client) {
+		c.retry.MaxAttempts = attempts
+	}
+}
+
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+// 实际等待时间在指数增长的上限内随机取值；服务端返回 Retry-After 时以它为准（不超过 max）。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(c *Client) {
+		c.retry.InitialBackoff = initial
+		c.retry.MaxBackoff = max
+	}
+}
+
+// --- Client Constructor ---
+
+// New 创建并返回一个新的Anthropic Client实例。
+// API密钥按以下顺序查找：WithToken 选项、ANTHROPIC_API_KEY 环境变量。
+func New(opts ...Option) (*Client, error) {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		version:    DefaultVersion,
+		httpClient: http.DefaultClient,
+		retry:      httpretry.DefaultPolicy(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
+	if c.apikey == "" {
+		c.apikey = strings.TrimSpace(os.Getenv(apiKeyEnvVar))
+	}
+	if c.apikey == "" {
+		return nil, fmt.Errorf("%w (tried: WithToken option, env %s)", ErrAPIKeyNotFound, apiKeyEnvVar)
+	}
+	return c, nil
+}
+
+// --- Request and Response Payloads ---
+
+// 内容块的类型。
+const (
+	BlockTypeText       = "text"
+	BlockTypeToolUse    = "tool_use"
+	BlockTypeToolResult = "tool_result"
+)
+
+// Message 结构体表示对话中的一条消息，角色只能是 "user" 或 "assistant"。
+type Message struct {
+	Role    string         `json:"role"`
+	Content []ContentBlock `json:"content"`
+}
+
+// ContentBlock 结构体表示消息中的一个内容块，不同类型使用不同的字段：
+// text 使用 Text；tool_use 使用 ID、Name、Input；tool_result 使用 ToolUseID、Content。
+type ContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"` // 工具参数，是一个JSON对象
+
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"` // 工具的执行结果
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+// Tool 结构体表示请求中声明的一个工具。
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"` // 参数的JSON Schema
+}
+
+// ToolChoice 结构体对应请求中的tool_choice字段，
+// Type 为 "auto"、"any"、"none" 或 "tool"（此时需要指定 Name）。
+type ToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// MessagesRequest 结构体定义了发送到 /v1/messages 的请求体。
+type MessagesRequest struct {
+	Model     string    `json:"model"`
+	Messages  []Message `json:"messages"`
+	System    string    `json:"system,omitempty"` // 系统提示位于顶层，而不是消息列表中
+	MaxTokens int       `json:"max_tokens"`       // 必填，为0时使用 DefaultMaxTokens
+	Stream    bool      `json:"stream,omitempty"`
+
+	// 以下为可选的采样参数，为nil时不会出现在请求体中。
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"top_p,omitempty"`
+	TopK          *int     `json:"top_k,omitempty"`
+	StopSequences []string `json:"stop_sequences,omitempty"`
+
+	Tools      []Tool      `json:"tools,omitempty"`
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+
+	// StreamingFunc 不为空时以流式方式请求，每收到一段增量文本就回调一次。
+	// 回调返回错误会中止读取流。
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
+}
+
+// Usage 结构体表示本次API调用的token使用情况。
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// MessagesResponse 结构体是 /v1/messages 返回的响应。
+type MessagesResponse struct {
+	ID           string         `json:"id"`
+	Type         string         `json:"type"`
+	Role         string         `json:"role"`
+	Model        string         `json:"model"`
+	Content      []ContentBlock `json:"content"`
+	StopReason   string         `json:"stop_reason"` // "end_turn"、"max_tokens"、"stop_sequence"、"tool_use" 等
+	StopSequence string         `json:"stop_sequence"`
+	Usage        Usage          `json:"usage"`
+}
+
+// Text 返回响应中所有文本块拼接后的内容。
+func (r *MessagesResponse) Text() string {
+	var b strings.Builder
+	for _, block := range r.Content {
+		if block.Type == BlockTypeText {
+			b.WriteString(block.Text)
+		}
+	}
+	return b.String()
+}
+
+// --- Internal HTTP Request Method ---
+
+// doMessages 负责向Anthropic API发送实际的HTTP请求，非200响应统一转换为 *APIError。
+func (c *Client) doMessages(ctx context.Context, payload *MessagesRequest) (*MessagesResponse, error) {
+	if payload.Model == "" {
+		payload.Model = DefaultChatModel
+	}
+	if payload.MaxTokens == 0 {
+		payload.MaxTokens = DefaultMaxTokens
+	}
+
+	// 设置了超时时间时，为本次请求派生一个带超时的ctx。
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	// 只有设置了 StreamingFunc 时才使用流式传输。
+	payload.Stream = payload.StreamingFunc != nil
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON payload: %w", err)
+	}
+
+	url := c.baseURL + messagesAPIPath
+	r, err := httpretry.Do(ctx, c.httpClient, c.retry, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payloadBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-api-key", c.apikey)
+		req.Header.Set("anthropic-version", c.version)
+		for key, values := range c.headers {
+			req.Header[key] = values
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, newAPIError(r)
+	}
+
+	if payload.Stream {
+		return parseStream(ctx, r.Body, payload.StreamingFunc)
+	}
+
+	var response MessagesResponse
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode Anthropic API response: %w", err)
+	}
+	return &response, nil
+}
+
+// --- Public Messages Method ---
+
+// CreateMessage 发送消息请求并返回完整的响应，流式请求返回的是聚合之后的响应。
+func (c *Client) CreateMessage(ctx context.Context, r *MessagesRequest) (*MessagesResponse, error) {
+	resp, err := c.doMessages(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Content) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return resp, nil
+}
@@ -0,0 +1,47 @@
+package anthropicclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/internal/httpretry"
+	"github.com/zideajang/langChaingo/llms/internal/httputil"
+)
+
+// providerName 是APIError中使用的供应商名称。
+const providerName = "Anthropic"
+
+// APIError 是Anthropic接口返回非200响应时的错误类型，与 llms.APIError 相同。
+type APIError = llms.APIError
+
+// errorPayload 是Anthropic的错误响应体结构，流式响应中的 error 事件也使用这个结构：
+// {"type":"error","error":{"type":"overloaded_error","message":"..."}}
+type errorPayload struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// newAPIError 读取错误响应并构造 APIError。
+func newAPIError(r *http.Response) *APIError {
+	body := httputil.ReadErrorBody(r.Body)
+	apiErr := &APIError{
+		Provider:   providerName,
+		StatusCode: r.StatusCode,
+		RequestID:  httputil.RequestID(r.Header),
+		Retryable:  httpretry.IsRetryableStatus(r.StatusCode),
+	}
+
+	var payload errorPayload
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error.Message != "" {
+		apiErr.Message = payload.Error.Message
+		apiErr.Type = payload.Error.Type
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
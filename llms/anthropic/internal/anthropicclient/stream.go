@@ -0,0 +1,150 @@
+package anthropicclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// streamEvent 结构体用于解析流式响应中每一个 data: 行的JSON数据，
+// 不同的事件类型使用不同的字段。
+type streamEvent struct {
+	Type string `json:"type"`
+
+	// message_start 携带响应的元数据和输入token数。
+	Message *MessagesResponse `json:"message"`
+
+	// content_block_start、content_block_delta 通过 Index 关联到同一个内容块。
+	Index        int           `json:"index"`
+	ContentBlock *ContentBlock `json:"content_block"`
+	Delta        *streamDelta  `json:"delta"`
+
+	// message_delta 携带最终的输出token数。
+	Usage *Usage `json:"usage"`
+
+	// error 事件携带错误信息。
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// streamDelta 是增量内容：文本块为 text_delta，工具调用的参数为 input_json_delta，
+// message_delta 事件中则携带 stop_reason。
+type streamDelta struct {
+	Type         string `json:"type"`
+	Text         string `json:"text"`
+	PartialJSON  string `json:"partial_json"`
+	StopReason   string `json:"stop_reason"`
+	StopSequence string `json:"stop_sequence"`
+}
+
+// parseStream 解析 /v1/messages 返回的SSE流，流以 message_stop 事件结束。
+// 返回值是把所有增量内容聚合之后的完整响应。
+func parseStream(ctx context.Context, body io.Reader, fn func(ctx context.Context, chunk []byte) error) (*MessagesResponse, error) {
+	var (
+		response MessagesResponse
+		// inputs 按内容块的下标累积工具调用参数的JSON片段。
+		inputs = map[int]*strings.Builder{}
+		done   bool
+	)
+	scanner := bufio.NewScanner(body)
+	// 单行可能较长，放宽默认的64KB限制。
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// 只需要 data: 行，event: 行中的类型与data中的type字段相同。
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		var event streamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode Anthropic stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				response = *event.Message
+				response.Content = nil
+			}
+		case "content_block_start":
+			if event.ContentBlock == nil {
+				continue
+			}
+			// 内容块按下标依次开始，下标只能指向已有的块或者下一个块。
+			if event.Index < 0 || event.Index > len(response.Content) {
+				return nil, fmt.Errorf("failed to decode Anthropic stream event: invalid content block index %d", event.Index)
+			}
+			if event.Index == len(response.Content) {
+				response.Content = append(response.Content, ContentBlock{})
+			}
+			block := *event.ContentBlock
+			// tool_use 块的 input 在开始时为空对象，完整参数由之后的 input_json_delta 拼接而成。
+			if block.Type == BlockTypeToolUse {
+				block.Input = nil
+				inputs[event.Index] = &strings.Builder{}
+			}
+			response.Content[event.Index] = block
+		case "content_block_delta":
+			if event.Delta == nil {
+				continue
+			}
+			if event.Index < 0 || event.Index >= len(response.Content) {
+				return nil, fmt.Errorf("failed to decode Anthropic stream event: invalid content block index %d", event.Index)
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				response.Content[event.Index].Text += event.Delta.Text
+				if err := fn(ctx, []byte(event.Delta.Text)); err != nil {
+					return nil, fmt.Errorf("streaming func returned an error: %w", err)
+				}
+			case "input_json_delta":
+				if b, ok := inputs[event.Index]; ok {
+					b.WriteString(event.Delta.PartialJSON)
+				}
+			}
+		case "message_delta":
+			if event.Delta != nil {
+				response.StopReason = event.Delta.StopReason
+				response.StopSequence = event.Delta.StopSequence
+			}
+			if event.Usage != nil {
+				response.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "message_stop":
+			done = true
+		case "error":
+			apiErr := &APIError{Provider: providerName}
+			if event.Error != nil {
+				apiErr.Type = event.Error.Type
+				apiErr.Message = event.Error.Message
+				apiErr.Retryable = event.Error.Type == "overloaded_error"
+			}
+			return nil, apiErr
+		}
+		if done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Anthropic stream: %w", err)
+	}
+	if !done {
+		return nil, fmt.Errorf("Anthropic stream ended before message_stop")
+	}
+
+	for i, b := range inputs {
+		input := b.String()
+		if input == "" {
+			input = "{}"
+		}
+		response.Content[i].Input = json.RawMessage(input)
+	}
+	return &response, nil
+}
@@ -0,0 +1,103 @@
+package anthropicclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+func discard(context.Context, []byte) error { return nil }
+
+const streamStart = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[],"usage":{"input_tokens":10,"output_tokens":1}}}
+
+`
+
+func TestParseStream(t *testing.T) {
+	body := streamStart + `event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hel"}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"lo"}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":12}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+	var chunks []string
+	resp, err := parseStream(context.Background(), strings.NewReader(body), func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(chunks, "|"); got != "Hel|lo" {
+		t.Errorf("chunks = %q", got)
+	}
+	if resp.ID != "msg_1" || resp.StopReason != "tool_use" || resp.Usage.InputTokens != 10 || resp.Usage.OutputTokens != 12 {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.Content) != 2 || resp.Content[0].Text != "Hello" ||
+		resp.Content[1].Name != "get_weather" || string(resp.Content[1].Input) != `{"city":"Paris"}` {
+		t.Errorf("content = %+v", resp.Content)
+	}
+}
+
+func TestParseStreamTruncated(t *testing.T) {
+	body := streamStart + `event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+`
+	if _, err := parseStream(context.Background(), strings.NewReader(body), discard); err == nil {
+		t.Error("stream without message_stop succeeded, want error")
+	}
+}
+
+func TestParseStreamInvalidIndex(t *testing.T) {
+	events := []string{
+		`{"type":"content_block_start","index":-1,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_start","index":3,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":-1,"delta":{"type":"text_delta","text":"x"}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"x"}}`,
+	}
+	for _, event := range events {
+		body := streamStart + "data: " + event + "\n\ndata: {\"type\":\"message_stop\"}\n\n"
+		if _, err := parseStream(context.Background(), strings.NewReader(body), discard); err == nil {
+			t.Errorf("event %s succeeded, want error", event)
+		}
+	}
+}
+
+func TestParseStreamError(t *testing.T) {
+	body := streamStart + `event: error
+data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}
+
+`
+	_, err := parseStream(context.Background(), strings.NewReader(body), discard)
+	var apiErr *llms.APIError
+	if !errors.As(err, &apiErr) || apiErr.Type != "overloaded_error" || !apiErr.Retryable {
+		t.Errorf("error = %v, want retryable overloaded_error", err)
+	}
+}
@@ -0,0 +1,63 @@
+package llms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BatchLLM 表示支持批量生成并返回每个提示各自结果的模型。
+// 与 Generate 不同，单个提示失败不会影响其他提示的结果。
+type BatchLLM interface {
+	GenerateBatch(ctx context.Context, prompts []string, opts ...CallOption) []BatchResult
+}
+
+// BatchResult 是批量生成中单个提示的结果，Err 不为nil时表示该提示失败。
+type BatchResult struct {
+	Index      int              // 提示在输入中的下标
+	Prompt     string           // 原始提示
+	Completion string           // 生成的文本，失败时为空
+	Response   *ContentResponse // 完整响应，失败时为nil
+	Err        error
+}
+
+// BatchError 汇总了批量生成中失败的提示。
+// 它实现了 Unwrap() []error，可以配合 errors.Is / errors.As 检查单个提示的错误。
+type BatchError struct {
+	Total  int           // 提示总数
+	Failed []BatchResult // 失败的提示
+}
+
+func (e *BatchError) Error() string {
+	parts := make([]string, 0, len(e.Failed))
+	for _, r := range e.Failed {
+		parts = append(parts, r.Err.Error())
+	}
+	return fmt.Sprintf("%d of %d prompts failed: %s", len(e.Failed), e.Total, strings.Join(parts, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, r := range e.Failed {
+		errs = append(errs, r.Err)
+	}
+	return errs
+}
+
+// BatchCompletions 从批量结果中取出生成的文本，顺序与输入一致。
+// 有提示失败时，仍然返回其余提示的结果（失败的位置为空字符串），同时返回 *BatchError。
+func BatchCompletions(results []BatchResult) ([]string, error) {
+	completions := make([]string, len(results))
+	var failed []BatchResult
+	for i, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+			continue
+		}
+		completions[i] = r.Completion
+	}
+	if len(failed) > 0 {
+		return completions, &BatchError{Total: len(results), Failed: failed}
+	}
+	return completions, nil
+}
@@ -3,17 +3,34 @@ package deepseekLLM
 import (
 	"context"
 	"fmt"
-	"sync"
+	"net/http"
+	"time"
 
+	"github.com/zideajang/langChaingo/callbacks"
+	"github.com/zideajang/langChaingo/llms"
 	"github.com/zideajang/langChaingo/llms/deepseek/internal/deepseekclient" // Import the new deepseekclient
+	"github.com/zideajang/langChaingo/llms/internal/openaiclient"
+	"github.com/zideajang/langChaingo/llms/internal/runner"
 )
 
 // DeepSeekLLM 结构体封装了 DeepSeek 客户端和模型配置。
 type DeepSeekLLM struct {
 	client *deepseekclient.Client
 	model  string //模型名称，提供选择的是 deepseek-chat /deepseek-reason
+
+	// clientOptions 在创建内部客户端时传给 deepseekclient.New
+	clientOptions []deepseekclient.Option
+
+	// runner 负责回调、客户端限流和批量生成
+	runner runner.Runner
 }
 
+var (
+	_ llms.ContextLLM = (*DeepSeekLLM)(nil)
+	_ llms.ChatModel  = (*DeepSeekLLM)(nil)
+	_ llms.BatchLLM   = (*DeepSeekLLM)(nil)
+)
+
 // Option 类型定义了用于配置 DeepSeekLLM 实例的函数选项。
 type Option func(*DeepSeekLLM)
 
@@ -22,7 +39,8 @@ type Option func(*DeepSeekLLM)
 func New(opts ...Option) (*DeepSeekLLM, error) {
 	// llm 的指针
 	llm := &DeepSeekLLM{
-		model: deepseekclient.DefaultChatModel, // 设置默认模型
+		model:  deepseekclient.DefaultChatModel, // 设置默认模型
+		runner: runner.New("DeepSeek"),
 	}
 
 	// 应用所有传入的选项来更新 LLM 配置
@@ -31,8 +49,8 @@ func New(opts ...Option) (*DeepSeekLLM, error) {
 	}
 
 	// 初始化 DeepSeek API 客户端
-	// 注意：deepseekclient.New() 现在从配置文件中读取API密钥，所以不需要参数
-	client, err := deepseekclient.New()
+	// API 密钥依次从 WithToken、DEEPSEEK_API_KEY 环境变量、YAML 配置文件中查找
+	client, err := deepseekclient.New(llm.clientOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create DeepSeek client: %w", err)
 	}
@@ -47,77 +65,150 @@ func WithModel(model string) Option {
 	}
 }
 
-// Call 方法实现了 llms.LLM 接口的 Call 方法，用于向 DeepSeek 模型发送单个提示。
-func (l *DeepSeekLLM) Call(prompt string) (string, error) {
-	ctx := context.Background()
+// WithToken 直接指定 DeepSeek API 密钥，优先于环境变量和配置文件。
+func WithToken(token string) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.clientOptions = append(llm.clientOptions, deepseekclient.WithToken(token))
+	}
+}
 
-	// 构建 DeepSeek 聊天请求
-	req := &deepseekclient.ChatRequest{
-		Model: l.model,
-		Messages: []deepseekclient.Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Stream: false, // 强制为非流式响应
+// WithConfigPath 指定保存 API 密钥的 YAML 配置文件路径，支持以 ~ 开头的路径。
+// 未指定时使用用户配置目录下的 tinychaingo/config.yaml。
+func WithConfigPath(path string) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.clientOptions = append(llm.clientOptions, deepseekclient.WithConfigPath(path))
 	}
+}
 
-	// 调用内部 DeepSeek 客户端的 Chat 方法
-	resp, err := l.client.Chat(ctx, req)
-	if err != nil {
-		return "", fmt.Errorf("DeepSeek Chat failed: %w", err)
+// WithBaseURL 指定DeepSeek服务的基础URL，可用于接入兼容 DeepSeek 接口的网关或代理。
+func WithBaseURL(baseURL string) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.clientOptions = append(llm.clientOptions, deepseekclient.WithBaseURL(baseURL))
 	}
+}
 
-	return resp.Content, nil
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义 Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.clientOptions = append(llm.clientOptions, deepseekclient.WithHTTPClient(httpClient))
+	}
 }
 
-// Generate 方法实现了 llms.LLM 接口的 Generate 方法，用于向 DeepSeek 模型批量发送提示。
-// 它通过并发 Goroutine 来提高效率。
-func (l *DeepSeekLLM) Generate(prompts []string) ([]string, error) {
-	completions := make([]string, len(prompts)) // 存储所有完成的文本
-	var wg sync.WaitGroup                       // 用于等待所有 Goroutine 完成
-	errs := make(chan error, len(prompts))      // 缓冲通道，用于收集并发 Goroutine 中的错误
+// WithHeader 为每个请求附加一个自定义请求头。
+func WithHeader(key, value string) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.clientOptions = append(llm.clientOptions, deepseekclient.WithHeader(key, value))
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间。
+func WithTimeout(timeout time.Duration) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.clientOptions = append(llm.clientOptions, deepseekclient.WithTimeout(timeout))
+	}
+}
 
-	for i, prompt := range prompts {
-		wg.Add(1) // 增加 WaitGroup 计数器
+// WithStreamUsage 设置流式请求是否发送 stream_options.include_usage，让响应带上 token 用量，默认开启。
+// 通过不支持这个字段的网关访问时可以传入 false 关闭。
+func WithStreamUsage(enabled bool) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.clientOptions = append(llm.clientOptions, deepseekclient.WithStreamUsage(enabled))
+	}
+}
 
-		go func(i int, p string) {
-			defer wg.Done() // Goroutine 完成时，减少 WaitGroup 计数器
-			ctx := context.Background()
+// WithMaxConcurrency 设置 Generate 批量生成时同时进行的最大请求数，默认为 8，小于等于 0 时不限制。
+func WithMaxConcurrency(n int) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.runner.MaxConcurrency = n
+	}
+}
 
-			req := &deepseekclient.ChatRequest{
-				Model: l.model,
-				Messages: []deepseekclient.Message{
-					{
-						Role:    "user",
-						Content: p,
-					},
-				},
-				Stream: false, // 强制为非流式响应
-			}
+// WithRateLimit 开启客户端限流，限制每分钟的请求数和 token 数，传入 0 表示不限制该项。
+// 发出请求前按估算的 token 数预留配额，拿到响应后按实际用量修正。
+// 同一个实例上的所有调用共享这一限额。
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.runner.SetRateLimit(requestsPerMinute, tokensPerMinute)
+	}
+}
 
-			resp, err := l.client.Chat(ctx, req)
-			if err != nil {
-				errs <- fmt.Errorf("DeepSeek Generate for prompt %d failed: %w", i, err)
-				return
-			}
-			completions[i] = resp.Content
-		}(i, prompt)
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1 表示不重试，默认为 3。
+// 429、5xx 以及连接错误会按照带随机抖动的指数退避重试。
+func WithMaxAttempts(attempts int) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.clientOptions = append(llm.clientOptions, deepseekclient.WithMaxAttempts(attempts))
 	}
+}
 
-	wg.Wait()   // 等待所有 Goroutine 完成执行
-	close(errs) // 关闭错误通道，表示不会再有错误发送
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.clientOptions = append(llm.clientOptions, deepseekclient.WithRetryBackoff(initial, max))
+	}
+}
 
-	var allErrors []error
-	// 从错误通道中收集所有错误
-	for err := range errs {
-		allErrors = append(allErrors, err)
+// WithCallbacks 注册接收这个实例上所有调用事件的 Handler。
+// 只想观察某一次调用时，可以使用 callbacks.WithHandler 把 Handler 放进 context。
+func WithCallbacks(h callbacks.Handler) Option {
+	return func(llm *DeepSeekLLM) {
+		llm.runner.Callbacks = h
 	}
+}
+
+// Call 方法实现了 llms.LLM 接口的 Call 方法，是 CallContext 的简单封装。
+func (l *DeepSeekLLM) Call(prompt string, opts ...llms.CallOption) (string, error) {
+	return l.CallContext(context.Background(), prompt, opts...)
+}
 
-	if len(allErrors) > 0 {
-		return nil, fmt.Errorf("multiple errors during DeepSeek Generate: %v", allErrors)
+// CallContext 使用调用方传入的 ctx 向 DeepSeek 模型发送单个提示，
+// ctx 被取消或超时后，正在进行的 HTTP 请求会随之中断。
+func (l *DeepSeekLLM) CallContext(ctx context.Context, prompt string, opts ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, l, prompt, opts...)
+}
+
+// GenerateContent 发送一组多轮对话消息（系统提示、历史回复等），
+// 消息会被映射为 OpenAI 兼容请求中的 Messages。
+func (l *DeepSeekLLM) GenerateContent(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error) {
+	return l.runner.GenerateContent(ctx, messages, opts, l.generateContent)
+}
+
+func (l *DeepSeekLLM) generateContent(ctx context.Context, messages []llms.Message, callOpts *llms.CallOptions) (*llms.ContentResponse, error) {
+	// 构建 DeepSeek 聊天请求。DeepSeek 不支持 top_k，该参数会被忽略；
+	// 它只支持 json_object，设置了 JSONSchema 时同样退化为 JSON 模式。
+	req := openaiclient.NewChatRequest(l.modelFor(callOpts), messages, callOpts, false)
+
+	// 调用内部 DeepSeek 客户端的 Chat 方法
+	start := time.Now()
+	resp, err := l.client.Chat(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("DeepSeek Chat failed: %w", err)
 	}
 
-	return completions, nil
+	// DeepSeek 不上报服务端耗时，只记录客户端测量的延迟。
+	return resp.ContentResponse(time.Since(start)), nil
+}
+
+// Generate 方法实现了 llms.LLM 接口的 Generate 方法，是 GenerateContext 的简单封装。
+func (l *DeepSeekLLM) Generate(prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return l.GenerateContext(context.Background(), prompts, opts...)
+}
+
+// GenerateContext 为每一个提示调用 GenerateContent，所有请求共享同一个 ctx。
+// 同时进行的请求数受 WithMaxConcurrency 限制。
+// 部分提示失败时仍然返回其余提示的结果（失败的位置为空字符串），同时返回 *llms.BatchError。
+func (l *DeepSeekLLM) GenerateContext(ctx context.Context, prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return llms.BatchCompletions(l.GenerateBatch(ctx, prompts, opts...))
+}
+
+// GenerateBatch 并发地为每一个提示生成结果，并按输入顺序返回每个提示各自的成功或失败。
+func (l *DeepSeekLLM) GenerateBatch(ctx context.Context, prompts []string, opts ...llms.CallOption) []llms.BatchResult {
+	return l.runner.GenerateBatch(ctx, prompts, opts, l.GenerateContent)
+}
+
+// modelFor 返回本次调用实际使用的模型名称，调用选项优先于实例配置。
+func (l *DeepSeekLLM) modelFor(opts *llms.CallOptions) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return l.model
 }
@@ -1,206 +1,147 @@
 package deepseekclient
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
 	"net/http"
-	"os" // For reading config file
+	"time"
 
-	// For path manipulation
-	"gopkg.in/yaml.v3" // For parsing YAML config
+	"github.com/zideajang/langChaingo/llms/internal/openaiclient"
 )
 
 // --- Constants ---
 const (
 	// DefaultChatModel 是DeepSeek客户端使用的默认模型。
 	DefaultChatModel = "deepseek-chat"
-	// chatAPIPath 是DeepSeek API中用于聊天补全的路由。
-	chatAPIPath = "/chat/completions"
 	// DefaultBaseURL 是DeepSeek服务的默认基础URL。
 	DefaultBaseURL = "https://api.deepseek.com"
-	// configFilePath 是DeepSeek API密钥的配置文件路径。
-	configFilePath = "D:/config.yaml" // Adjust this path as needed
-	// deepseekAPIKeyEnvVar 是在config.yaml中查找DeepSeek API Key的关键字。
-	// deepseekAPIKeyConfigKey = "DEEPSEEK_API_KEY"
+	// providerName 是错误信息和APIError中使用的供应商名称。
+	providerName = "DeepSeek"
 )
 
 // --- Errors ---
 // ErrEmptyResponse 表示DeepSeek模型返回的内容为空。
-var ErrEmptyResponse = errors.New("empty response from DeepSeek")
+var ErrEmptyResponse = openaiclient.ErrEmptyResponse
 
-// ErrAPIKeyNotFound 表示在配置文件中未找到DeepSeek API Key。
-var ErrAPIKeyNotFound = errors.New("DeepSeek API Key not found in config file")
+// ErrAPIKeyNotFound 表示在所有来源中都没有找到DeepSeek API Key。
+// New 返回的错误会包装它，并说明尝试过哪些来源。
+var ErrAPIKeyNotFound = errors.New("DeepSeek API Key not found")
+
+// --- Request and Response Payloads (OpenAI-compatible) ---
+
+// DeepSeek 的接口与OpenAI兼容，请求、响应和错误类型直接使用 openaiclient 中的定义。
+type (
+	APIError     = openaiclient.APIError
+	ChatRequest  = openaiclient.ChatRequest
+	ChatResponse = openaiclient.ChatResponse
+)
 
 // --- Client Structure ---
 
 // Client 表示与DeepSeek API交互的客户端。
+// 请求的发送、重试和流式解析由内嵌的 openaiclient.Client 完成，
+// 这里只负责查找API密钥和设置DeepSeek的默认值。
 type Client struct {
-	apikey  string // DeepSeek API密钥
-	baseURL string // DeepSeek服务的基准URL
-}
+	*openaiclient.Client
 
-// --- Config Structure for YAML Parsing ---
-type Config struct {
-	DeepSeekAPIKey string `yaml:"DEEPSEEK_API_KEY"`
+	apikey     string                // DeepSeek API密钥
+	configPath string                // 保存API密钥的YAML配置文件路径，为空时使用默认路径
+	options    []openaiclient.Option // 传给 openaiclient.New 的选项
 }
 
-// --- Client Constructor ---
-
-// New 创建并返回一个新的DeepSeek Client实例。
-// 它会从指定路径的config.yaml文件中读取API密钥。
-func New() (*Client, error) {
-	// Read API key from config file
-	// 读取 yaml 文件
-	configBytes, err := os.ReadFile(configFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file at %s: %w", configFilePath, err)
-	}
-
-	var config Config
-	if err := yaml.Unmarshal(configBytes, &config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config file: %w", err)
-	}
-
-	if config.DeepSeekAPIKey == "" {
-		return nil, ErrAPIKeyNotFound
-	}
+// Option 是用于配置Client的函数选项。
+type Option func(*Client)
 
-	c := &Client{
-		apikey:  config.DeepSeekAPIKey, //直接把 API 写道这里，
-		baseURL: DefaultBaseURL,
+// WithToken 直接指定API密钥，优先级最高。
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.apikey = token
 	}
-	return c, nil
 }
 
-// --- Request and Response Payloads (OpenAI-compatible) ---
-
-// Message 结构体表示聊天中的一条消息。
-type Message struct {
-	Role    string `json:"role"`    // 消息发送者的角色 (e.g., "user", "system", "assistant")
-	Content string `json:"content"` // 消息的文本内容
-}
-
-// ChatRequest 结构体定义了发送到DeepSeek API的聊天请求体。
-// 它与OpenAI的API请求体兼容。
-type ChatRequest struct {
-	Model    string    `json:"model"`    // 要使用的DeepSeek模型名称
-	Messages []Message `json:"messages"` // 聊天消息列表
-	Stream   bool      `json:"stream"`   // 是否以流式方式获取响应 (false表示获取完整响应)
+// WithConfigPath 指定保存API密钥的YAML配置文件路径，支持以 ~ 开头的路径。
+func WithConfigPath(path string) Option {
+	return func(c *Client) {
+		c.configPath = path
+	}
 }
 
-// ChatChoice 结构体表示聊天补全的一个选项。
-type ChatChoice struct {
-	Index        int     `json:"index"`
-	Message      Message `json:"message"`
-	FinishReason string  `json:"finish_reason"`
+// WithBaseURL 指定服务的基础URL，可用于接入DeepSeek兼容的网关或代理。
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.options = append(c.options, openaiclient.WithBaseURL(baseURL))
+	}
 }
 
-// Usage 结构体表示本次API调用的token使用情况。
-type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.options = append(c.options, openaiclient.WithHTTPClient(httpClient))
+	}
 }
 
-// DeepSeekChatResponsePayload 结构体用于解析DeepSeek API返回的完整JSON响应。
-// 它与OpenAI的API响应体兼容。
-type DeepSeekChatResponsePayload struct {
-	ID      string       `json:"id"`
-	Object  string       `json:"object"`
-	Created int64        `json:"created"`
-	Model   string       `json:"model"`
-	Choices []ChatChoice `json:"choices"`
-	Usage   Usage        `json:"usage"`
+// WithHeader 为每个请求附加一个自定义请求头。
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.options = append(c.options, openaiclient.WithHeader(key, value))
+	}
 }
 
-// ChatResponse 结构体是DeepSeek客户端向外部暴露的简化聊天响应。
-// 它只包含最重要的信息：LLM生成的内容。
-type ChatResponse struct {
-	Content string // LLM生成的内容
+// WithTimeout 设置单次请求的超时时间，流式请求的超时覆盖读取整个流的过程。
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.options = append(c.options, openaiclient.WithTimeout(timeout))
+	}
 }
 
-// this bind(object)
-// self.doChat()
-// type contains
-//
-// --- Internal HTTP Request Method ---
-
-// doChat 是一个内部方法，负责向DeepSeek API发送实际的HTTP聊天请求。
-// ctx 用于管理请求的生命周期和超时。
-// payload 包含要发送到DeepSeek的聊天请求数据。
-func (c *Client) doChat(ctx context.Context, payload *ChatRequest) (*DeepSeekChatResponsePayload, error) {
-	// 如果没有指定模型，则使用默认模型。
-	if payload.Model == "" {
-		payload.Model = DefaultChatModel
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1表示不重试。
+func WithMaxAttempts(attempts int) Option {
+	return func(c *Client) {
+		c.options = append(c.options, openaiclient.WithMaxAttempts(attempts))
 	}
+}
 
-	// 客户端期望DeepSeek API返回一个完整的、一次性的响应，而不是流式传输。
-	payload.Stream = false
-
-	// 将请求体转换为JSON字节数组。
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal JSON payload: %w", err)
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+// 实际等待时间在指数增长的上限内随机取值；服务端返回 Retry-After 时以它为准（不超过 max）。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(c *Client) {
+		c.options = append(c.options, openaiclient.WithRetryBackoff(initial, max))
 	}
+}
 
-	// 将字节数组包装成io.Reader，以便http.NewRequestWithContext使用。
-	body := bytes.NewReader(payloadBytes)
-
-	// 构建完整的请求URL。
-	url := c.baseURL + chatAPIPath
-
-	// 创建新的HTTP POST请求。
-	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+// WithStreamUsage 设置流式请求是否要求最后一个数据块携带 token 用量，默认开启。
+func WithStreamUsage(enabled bool) Option {
+	return func(c *Client) {
+		c.options = append(c.options, openaiclient.WithStreamUsage(enabled))
 	}
+}
 
-	// 设置请求头，指定内容类型为JSON和授权信息。
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apikey) // Add API Key
-
-	// 发送HTTP请求。
-	r, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
-	}
-	defer r.Body.Close() // 确保响应体在使用后关闭
+// --- Client Constructor ---
 
-	// 检查HTTP响应状态码。
-	if r.StatusCode != http.StatusOK {
-		respBodyBytes, _ := io.ReadAll(r.Body) // Read body for detailed error
-		return nil, fmt.Errorf("DeepSeek API request failed with status %d: %s", r.StatusCode, string(respBodyBytes))
+// New 创建并返回一个新的DeepSeek Client实例。
+// API密钥按以下顺序查找：WithToken 选项、DEEPSEEK_API_KEY 环境变量、YAML配置文件。
+func New(opts ...Option) (*Client, error) {
+	c := &Client{}
+	for _, opt := range opts {
+		opt(c)
 	}
 
-	// 声明一个变量来存储解析后的DeepSeek API响应。
-	var response DeepSeekChatResponsePayload
-	// 将HTTP响应体中的JSON数据解码到结构体中。
-	err = json.NewDecoder(r.Body).Decode(&response)
+	apikey, err := resolveAPIKey(c.apikey, c.configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode DeepSeek API response: %w", err)
+		return nil, err
 	}
-	return &response, nil
-}
-
-// --- Public Chat Method ---
-
-// Chat 方法是DeepSeek客户端的公共入口点，用于发送聊天请求。
-// 它调用内部的doChat方法并处理返回的响应。
-func (c *Client) Chat(ctx context.Context, r *ChatRequest) (*ChatResponse, error) {
-	resp, err := c.doChat(ctx, r)
+	c.apikey = apikey
+
+	// 默认值放在最前面，调用方的选项可以覆盖它们。
+	clientOpts := append([]openaiclient.Option{
+		openaiclient.WithBaseURL(DefaultBaseURL),
+		openaiclient.WithProviderName(providerName),
+		openaiclient.WithDefaultModel(DefaultChatModel),
+		openaiclient.WithAPIKey(apikey),
+		openaiclient.WithStreamUsage(true),
+	}, c.options...)
+	c.Client, err = openaiclient.New(clientOpts...)
 	if err != nil {
 		return nil, err
 	}
-	// 检查DeepSeek的响应是否包含有效的消息内容。
-	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
-		return nil, ErrEmptyResponse
-	}
-	// 返回一个简化的ChatResponse。
-	return &ChatResponse{
-		Content: resp.Choices[0].Message.Content,
-	}, nil
+	return c, nil
 }
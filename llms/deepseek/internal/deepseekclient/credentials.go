@@ -0,0 +1,107 @@
+package deepseekclient
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// apiKeyEnvVar 是保存DeepSeek API Key的环境变量名称。
+	apiKeyEnvVar = "DEEPSEEK_API_KEY"
+	// configDirName 是默认配置文件所在的目录名称，位于用户配置目录下。
+	configDirName = "tinychaingo"
+	// configFileName 是默认配置文件的文件名。
+	configFileName = "config.yaml"
+)
+
+// Config 是YAML配置文件的结构。
+type Config struct {
+	DeepSeekAPIKey string `yaml:"DEEPSEEK_API_KEY"`
+}
+
+// resolveAPIKey 按照 显式传入的token -> 环境变量 -> YAML配置文件 的顺序查找API密钥。
+// 所有来源都没有找到时返回包装了 ErrAPIKeyNotFound 的错误，并列出尝试过的来源。
+func resolveAPIKey(token, configPath string) (string, error) {
+	tried := []string{"WithToken option"}
+	if token != "" {
+		return token, nil
+	}
+
+	tried = append(tried, "env "+apiKeyEnvVar)
+	if key := strings.TrimSpace(os.Getenv(apiKeyEnvVar)); key != "" {
+		return key, nil
+	}
+
+	path, err := configFilePath(configPath)
+	switch {
+	case errors.Is(err, errNoUserConfigDir):
+		// 找不到用户配置目录时视为没有配置文件，例如 $HOME 未设置的容器环境。
+		tried = append(tried, "config file ("+err.Error()+")")
+	case err != nil:
+		return "", err
+	default:
+		tried = append(tried, "config file "+path)
+		key, err := readConfigFile(path)
+		if err != nil {
+			return "", err
+		}
+		if key != "" {
+			return key, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w (tried: %s)", ErrAPIKeyNotFound, strings.Join(tried, ", "))
+}
+
+// errNoUserConfigDir 表示未指定配置文件路径，并且无法确定用户配置目录。
+var errNoUserConfigDir = errors.New("user config dir not available")
+
+// configFilePath 返回实际使用的配置文件路径。
+// 未指定路径时使用用户配置目录（Linux 下遵循 $XDG_CONFIG_HOME，默认为 ~/.config）
+// 下的 tinychaingo/config.yaml，无法确定用户配置目录时返回包装了 errNoUserConfigDir 的错误。
+func configFilePath(path string) (string, error) {
+	if path != "" {
+		return expandHome(path)
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errNoUserConfigDir, err)
+	}
+	return filepath.Join(dir, configDirName, configFileName), nil
+}
+
+// expandHome 把以 ~ 开头的路径展开为用户主目录。
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %s: %w", path, err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// readConfigFile 从YAML配置文件中读取API密钥。
+// 文件不存在时返回空字符串，交由调用方报告 ErrAPIKeyNotFound。
+func readConfigFile(path string) (string, error) {
+	configBytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file at %s: %w", path, err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(configBytes, &config); err != nil {
+		return "", fmt.Errorf("failed to unmarshal config file %s: %w", path, err)
+	}
+	return strings.TrimSpace(config.DeepSeekAPIKey), nil
+}
@@ -0,0 +1,86 @@
+package deepseekclient
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestResolveAPIKey 检查 WithToken、环境变量、配置文件的查找顺序。
+func TestResolveAPIKey(t *testing.T) {
+	dir := t.TempDir()
+	configWithKey := filepath.Join(dir, "with_key.yaml")
+	if err := os.WriteFile(configWithKey, []byte("DEEPSEEK_API_KEY: \" file-key \"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configEmpty := filepath.Join(dir, "empty.yaml")
+	if err := os.WriteFile(configEmpty, []byte("OTHER: x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configInvalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(configInvalid, []byte("DEEPSEEK_API_KEY: [\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.yaml")
+
+	tests := []struct {
+		name       string
+		token      string
+		env        string
+		configPath string
+		want       string
+		wantErr    error  // 期望错误匹配的哨兵错误
+		wantErrMsg string // 期望错误信息包含的内容
+	}{
+		{name: "token wins", token: "token-key", env: "env-key", configPath: configWithKey, want: "token-key"},
+		{name: "env before config", env: " env-key ", configPath: configWithKey, want: "env-key"},
+		{name: "config file", configPath: configWithKey, want: "file-key"},
+		{name: "config without key", configPath: configEmpty, wantErr: ErrAPIKeyNotFound, wantErrMsg: "config file " + configEmpty},
+		{name: "missing config", configPath: missing, wantErr: ErrAPIKeyNotFound, wantErrMsg: "WithToken option, env DEEPSEEK_API_KEY, config file " + missing},
+		{name: "invalid config", configPath: configInvalid, wantErrMsg: "failed to unmarshal config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(apiKeyEnvVar, tt.env)
+			got, err := resolveAPIKey(tt.token, tt.configPath)
+			if tt.wantErr == nil && tt.wantErrMsg == "" {
+				if err != nil || got != tt.want {
+					t.Fatalf("resolveAPIKey = %q, %v, want %q", got, err, tt.want)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("resolveAPIKey = %q, want error", got)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErrMsg) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+// TestConfigFilePath 检查 ~ 开头的路径会展开到用户主目录。
+func TestConfigFilePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"~", home},
+		{"~/deepseek.yaml", filepath.Join(home, "deepseek.yaml")},
+		{"/etc/deepseek.yaml", "/etc/deepseek.yaml"},
+		{"~other/deepseek.yaml", "~other/deepseek.yaml"},
+	}
+	for _, tt := range tests {
+		got, err := configFilePath(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("configFilePath(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package llms
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 以下哨兵错误用于按类别判断供应商返回的错误，配合 errors.Is 使用：
+//
+//	if errors.Is(err, llms.ErrRateLimited) { ... }
+var (
+	// ErrAuthentication 表示 API 密钥缺失、无效或没有权限。
+	ErrAuthentication = errors.New("llms: authentication failed")
+	// ErrInsufficientQuota 表示账户余额或配额不足。
+	ErrInsufficientQuota = errors.New("llms: insufficient quota")
+	// ErrRateLimited 表示请求被限流。
+	ErrRateLimited = errors.New("llms: rate limited")
+	// ErrContextLengthExceeded 表示提示与生成内容超出了模型的上下文长度。
+	ErrContextLengthExceeded = errors.New("llms: context length exceeded")
+	// ErrModelNotFound 表示请求的模型不存在或尚未下载。
+	ErrModelNotFound = errors.New("llms: model not found")
+	// ErrInvalidRequest 表示请求参数不合法。
+	ErrInvalidRequest = errors.New("llms: invalid request")
+	// ErrServerError 表示服务端内部错误或暂时不可用。
+	ErrServerError = errors.New("llms: server error")
+)
+
+// APIError 表示供应商接口返回的非 2xx 响应。
+// 可以通过 errors.As 取出详细信息，或者通过 errors.Is 与上面的哨兵错误比较。
+type APIError struct {
+	Provider   string // 供应商名称，例如 "ollama"、"deepseek"
+	StatusCode int    // HTTP 状态码
+	Code       string // 供应商返回的错误码，没有时为空
+	Type       string // 供应商返回的错误类型，没有时为空
+	Message    string // 供应商返回的错误信息
+	RequestID  string // 响应头中的请求 ID，便于向供应商反馈问题
+	Retryable  bool   // 该错误是否值得重试（限流、服务端临时错误等）
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s API request failed with status %d", e.Provider, e.StatusCode)
+	if e.Type != "" || e.Code != "" {
+		fmt.Fprintf(&b, " (type=%s code=%s)", e.Type, e.Code)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " [request id %s]", e.RequestID)
+	}
+	return b.String()
+}
+
+// Is 让 errors.Is(err, llms.ErrXxx) 可以判断错误类别。
+func (e *APIError) Is(target error) bool {
+	return target != nil && target == e.Kind()
+}
+
+// Kind 根据状态码、错误码和错误信息把错误归类到对应的哨兵错误，无法归类时返回 nil。
+func (e *APIError) Kind() error {
+	code := strings.ToLower(e.Code + " " + e.Type)
+	msg := strings.ToLower(e.Message)
+	switch {
+	case strings.Contains(code, "context_length") ||
+		strings.Contains(msg, "context length") ||
+		strings.Contains(msg, "context window") ||
+		strings.Contains(msg, "too many tokens") ||
+		strings.Contains(msg, "prompt is too long"):
+		return ErrContextLengthExceeded
+	case strings.Contains(code, "model_not_found") ||
+		(e.StatusCode == http.StatusNotFound && strings.Contains(msg, "model")):
+		return ErrModelNotFound
+	}
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuthentication
+	case http.StatusPaymentRequired:
+		return ErrInsufficientQuota
+	case http.StatusTooManyRequests:
+		if strings.Contains(code, "quota") {
+			return ErrInsufficientQuota
+		}
+		return ErrRateLimited
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrInvalidRequest
+	}
+	if e.StatusCode >= 500 {
+		return ErrServerError
+	}
+	return nil
+}
@@ -0,0 +1,495 @@
+package geminiLLM
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zideajang/langChaingo/callbacks"
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/gemini/internal/geminiclient"
+	"github.com/zideajang/langChaingo/llms/internal/runner"
+)
+
+// ErrPromptBlocked 表示提示被 Gemini 的安全策略拦截，没有生成任何候选回复。
+var ErrPromptBlocked = errors.New("gemini: prompt blocked")
+
+// ErrEmptyResponse 表示 Gemini 没有返回任何候选回复，并且提示也没有被拦截。
+var ErrEmptyResponse = errors.New("gemini: empty response")
+
+// SafetyRatingsKey 是 ContentResponse.GenerationInfo 中保存安全评估结果的键，值为 []SafetyRating。
+const SafetyRatingsKey = "safety_ratings"
+
+// SafetyRating 是 Gemini 对某一类有害内容的评估结果。
+type SafetyRating struct {
+	Category    string // 例如 HARM_CATEGORY_HARASSMENT
+	Probability string // NEGLIGIBLE、LOW、MEDIUM、HIGH
+	Blocked     bool   // 是否因为这一类内容被拦截
+}
+
+// SafetyRatings 取出响应中的安全评估结果，响应不是由 GeminiLLM 生成时返回 nil。
+func SafetyRatings(resp *llms.ContentResponse) []SafetyRating {
+	if resp == nil {
+		return nil
+	}
+	ratings, _ := resp.GenerationInfo[SafetyRatingsKey].([]SafetyRating)
+	return ratings
+}
+
+// GeminiLLM 结构体封装了 Gemini 客户端和模型配置。
+type GeminiLLM struct {
+	client *geminiclient.Client
+	model  string // 模型名称，例如 gemini-2.0-flash
+
+	// clientOptions 在创建内部客户端时传给 geminiclient.New
+	clientOptions []geminiclient.Option
+
+	// safetySettings 随每个请求发送的安全阈值
+	safetySettings []geminiclient.SafetySetting
+
+	// runner 负责回调、客户端限流和批量生成
+	runner runner.Runner
+}
+
+var (
+	_ llms.ContextLLM = (*GeminiLLM)(nil)
+	_ llms.ChatModel  = (*GeminiLLM)(nil)
+	_ llms.BatchLLM   = (*GeminiLLM)(nil)
+)
+
+// Option 类型定义了用于配置 GeminiLLM 实例的函数选项。
+type Option func(*GeminiLLM)
+
+// New 创建一个 GeminiLLM 实例。
+// API 密钥依次从 WithToken、GEMINI_API_KEY、GOOGLE_API_KEY 环境变量中查找。
+func New(opts ...Option) (*GeminiLLM, error) {
+	llm := &GeminiLLM{
+		model:  geminiclient.DefaultChatModel,
+		runner: runner.New("Gemini"),
+	}
+	for _, opt := range opts {
+		opt(llm)
+	}
+
+	client, err := geminiclient.New(llm.clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+	llm.client = client
+	return llm, nil
+}
+
+// WithModel 设置模型名称。
+func WithModel(model string) Option {
+	return func(llm *GeminiLLM) {
+		llm.model = model
+	}
+}
+
+// WithToken 直接指定 Gemini API 密钥，优先于环境变量。
+func WithToken(token string) Option {
+	return func(llm *GeminiLLM) {
+		llm.clientOptions = append(llm.clientOptions, geminiclient.WithToken(token))
+	}
+}
+
+// WithBaseURL 指定服务的基础URL（包含接口版本），默认为 https://generativelanguage.googleapis.com/v1beta。
+func WithBaseURL(baseURL string) Option {
+	return func(llm *GeminiLLM) {
+		llm.clientOptions = append(llm.clientOptions, geminiclient.WithBaseURL(baseURL))
+	}
+}
+
+// WithSafetySetting 设置某一类有害内容的拦截阈值，可以多次调用，
+// 例如 WithSafetySetting("HARM_CATEGORY_HARASSMENT", "BLOCK_ONLY_HIGH")。
+func WithSafetySetting(category, threshold string) Option {
+	return func(llm *GeminiLLM) {
+		llm.safetySettings = append(llm.safetySettings, geminiclient.SafetySetting{
+			Category:  category,
+			Threshold: threshold,
+		})
+	}
+}
+
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义 Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(llm *GeminiLLM) {
+		llm.clientOptions = append(llm.clientOptions, geminiclient.WithHTTPClient(httpClient))
+	}
+}
+
+// WithHeader 为每个请求附加一个自定义请求头。
+func WithHeader(key, value string) Option {
+	return func(llm *GeminiLLM) {
+		llm.clientOptions = append(llm.clientOptions, geminiclient.WithHeader(key, value))
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间。
+func WithTimeout(timeout time.Duration) Option {
+	return func(llm *GeminiLLM) {
+		llm.clientOptions = append(llm.clientOptions, geminiclient.WithTimeout(timeout))
+	}
+}
+
+// WithMaxConcurrency 设置 Generate 批量生成时同时进行的最大请求数，默认为 8，小于等于 0 时不限制。
+func WithMaxConcurrency(n int) Option {
+	return func(llm *GeminiLLM) {
+		llm.runner.MaxConcurrency = n
+	}
+}
+
+// WithRateLimit 开启客户端限流，限制每分钟的请求数和 token 数，传入 0 表示不限制该项。
+// 同一个实例上的所有调用共享这一限额。
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) Option {
+	return func(llm *GeminiLLM) {
+		llm.runner.SetRateLimit(requestsPerMinute, tokensPerMinute)
+	}
+}
+
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1 表示不重试，默认为 3。
+func WithMaxAttempts(attempts int) Option {
+	return func(llm *GeminiLLM) {
+		llm.clientOptions = append(llm.clientOptions, geminiclient.WithMaxAttempts(attempts))
+	}
+}
+
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(llm *GeminiLLM) {
+		llm.clientOptions = append(llm.clientOptions, geminiclient.WithRetryBackoff(initial, max))
+	}
+}
+
+// WithCallbacks 注册接收这个实例上所有调用事件的 Handler。
+func WithCallbacks(h callbacks.Handler) Option {
+	return func(llm *GeminiLLM) {
+		llm.runner.Callbacks = h
+	}
+}
+
+// Call 方法实现了 llms.LLM 接口的 Call 方法，是 CallContext 的简单封装。
+func (l *GeminiLLM) Call(prompt string, opts ...llms.CallOption) (string, error) {
+	return l.CallContext(context.Background(), prompt, opts...)
+}
+
+// CallContext 使用调用方传入的 ctx 向 Gemini 模型发送单个提示。
+func (l *GeminiLLM) CallContext(ctx context.Context, prompt string, opts ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, l, prompt, opts...)
+}
+
+// GenerateContent 发送一组多轮对话消息。系统消息会合并到 systemInstruction，
+// assistant 消息的角色为 "model"，工具结果作为 functionResponse 发送。
+// 提示被安全策略拦截时返回包装了 ErrPromptBlocked 的错误，没有任何候选回复时返回 ErrEmptyResponse；
+// 候选回复的安全评估结果可以通过 SafetyRatings 取出。
+func (l *GeminiLLM) GenerateContent(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error) {
+	return l.runner.GenerateContent(ctx, messages, opts, l.generateContent)
+}
+
+func (l *GeminiLLM) generateContent(ctx context.Context, messages []llms.Message, callOpts *llms.CallOptions) (*llms.ContentResponse, error) {
+	system, contents := toClientContents(messages)
+	req := &geminiclient.GenerateContentRequest{
+		Model:             l.modelFor(callOpts),
+		Contents:          contents,
+		SystemInstruction: system,
+		GenerationConfig:  toGenerationConfig(callOpts),
+		SafetySettings:    l.safetySettings,
+		Tools:             toClientTools(callOpts.Tools),
+		ToolConfig:        toToolConfig(callOpts.ToolChoice),
+		StreamingFunc:     callOpts.StreamingFunc,
+	}
+
+	start := time.Now()
+	resp, err := l.client.GenerateContent(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("Gemini GenerateContent failed: %w", err)
+	}
+	if len(resp.Candidates) == 0 {
+		if fb := resp.PromptFeedback; fb != nil && fb.BlockReason != "" {
+			return nil, fmt.Errorf("%w: %s", ErrPromptBlocked, fb.BlockReason)
+		}
+		return nil, ErrEmptyResponse
+	}
+	candidate := resp.Candidates[0]
+	toolCalls := fromClientParts(candidate.Content.Parts)
+
+	usage := resp.UsageMetadata
+	return &llms.ContentResponse{
+		Content:      resp.Text(),
+		Model:        resp.ModelVersion,
+		ID:           resp.ResponseID,
+		FinishReason: finishReason(candidate.FinishReason, len(toolCalls) > 0),
+		ToolCalls:    toolCalls,
+		Usage: llms.Usage{
+			PromptTokens:     usage.PromptTokenCount,
+			CompletionTokens: usage.CandidatesTokenCount + usage.ThoughtsTokenCount,
+			TotalTokens:      usage.TotalTokenCount,
+		},
+		// Gemini 不上报服务端耗时，只记录客户端测量的延迟。
+		Timings: llms.Timings{
+			Latency: time.Since(start),
+		},
+		GenerationInfo: map[string]any{
+			"response_id":            resp.ResponseID,
+			"model_version":          resp.ModelVersion,
+			"finish_reason":          candidate.FinishReason,
+			"prompt_token_count":     usage.PromptTokenCount,
+			"candidates_token_count": usage.CandidatesTokenCount,
+			"thoughts_token_count":   usage.ThoughtsTokenCount,
+			"total_token_count":      usage.TotalTokenCount,
+			SafetyRatingsKey:         fromClientSafetyRatings(candidate.SafetyRatings),
+		},
+	}, nil
+}
+
+// Generate 方法实现了 llms.LLM 接口的 Generate 方法，是 GenerateContext 的简单封装。
+func (l *GeminiLLM) Generate(prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return l.GenerateContext(context.Background(), prompts, opts...)
+}
+
+// GenerateContext 为每一个提示调用 GenerateContent，同时进行的请求数受 WithMaxConcurrency 限制。
+// 部分提示失败时仍然返回其余提示的结果（失败的位置为空字符串），同时返回 *llms.BatchError。
+func (l *GeminiLLM) GenerateContext(ctx context.Context, prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return llms.BatchCompletions(l.GenerateBatch(ctx, prompts, opts...))
+}
+
+// GenerateBatch 并发地为每一个提示生成结果，并按输入顺序返回每个提示各自的成功或失败。
+func (l *GeminiLLM) GenerateBatch(ctx context.Context, prompts []string, opts ...llms.CallOption) []llms.BatchResult {
+	return l.runner.GenerateBatch(ctx, prompts, opts, l.GenerateContent)
+}
+
+// modelFor 返回本次调用实际使用的模型名称，调用选项优先于实例配置。
+func (l *GeminiLLM) modelFor(opts *llms.CallOptions) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return l.model
+}
+
+// finishReason 把 Gemini 的 finishReason 统一为 llms 中的结束原因，
+// SAFETY、RECITATION 等其余值转换为小写后返回。
+func finishReason(reason string, hasToolCalls bool) string {
+	switch {
+	case hasToolCalls:
+		return llms.FinishReasonToolCalls
+	case reason == "STOP":
+		return llms.FinishReasonStop
+	case reason == "MAX_TOKENS":
+		return llms.FinishReasonLength
+	}
+	return strings.ToLower(reason)
+}
+
+// toGenerationConfig 把调用选项转换为 generationConfig，未设置的字段不会出现在请求体中。
+// 设置了 JSON 模式时输出类型为 application/json，有 schema 时同时通过 responseJsonSchema 约束输出。
+func toGenerationConfig(opts *llms.CallOptions) *geminiclient.GenerationConfig {
+	cfg := &geminiclient.GenerationConfig{
+		Temperature:      opts.Temperature,
+		TopP:             opts.TopP,
+		TopK:             opts.TopK,
+		MaxOutputTokens:  opts.MaxTokens,
+		StopSequences:    opts.StopWords,
+		Seed:             opts.Seed,
+		PresencePenalty:  opts.PresencePenalty,
+		FrequencyPenalty: opts.FrequencyPenalty,
+	}
+	if opts.JSONMode {
+		cfg.ResponseMIMEType = "application/json"
+		cfg.ResponseJSONSchema = opts.JSONSchema
+	}
+	return cfg
+}
+
+// toClientContents 把与供应商无关的消息转换为 Gemini 的 contents，并返回合并后的系统提示。
+// 相邻的同角色消息会合并为一条，例如同一轮中多个工具的结果。
+func toClientContents(messages []llms.Message) (*geminiclient.Content, []geminiclient.Content) {
+	var (
+		system []geminiclient.Part
+		out    []geminiclient.Content
+		// names 记录每个工具调用 ID 对应的函数名，functionResponse 需要通过函数名关联调用；
+		// Gemini 提供了调用 ID 时同时回传，使并行的多个同名调用能与各自的结果一一对应。
+		names = map[string]string{}
+	)
+	for _, m := range messages {
+		var (
+			role  string
+			parts []geminiclient.Part
+		)
+		switch m.Role {
+		case llms.RoleSystem:
+			system = append(system, geminiclient.Part{Text: m.Content})
+			continue
+		case llms.RoleTool:
+			role = "user"
+			name := m.Name
+			if name == "" {
+				name = names[m.ToolCallID]
+			}
+			parts = append(parts, geminiclient.Part{FunctionResponse: &geminiclient.FunctionResponse{
+				ID:       apiCallID(m.ToolCallID),
+				Name:     name,
+				Response: functionResponse(m.Content),
+			}})
+		case llms.RoleAssistant:
+			role = "model"
+			if m.Content != "" {
+				parts = append(parts, geminiclient.Part{Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				if tc.FunctionCall == nil {
+					continue
+				}
+				names[tc.ID] = tc.FunctionCall.Name
+				parts = append(parts, geminiclient.Part{FunctionCall: &geminiclient.FunctionCall{
+					ID:   apiCallID(tc.ID),
+					Name: tc.FunctionCall.Name,
+					Args: toRawArgs(tc.FunctionCall.Arguments),
+				}})
+			}
+		default:
+			role = "user"
+			parts = append(parts, geminiclient.Part{Text: m.Content})
+		}
+
+		// 接口不接受空的parts数组，没有任何内容的消息直接跳过。
+		if len(parts) == 0 {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Parts = append(out[n-1].Parts, parts...)
+			continue
+		}
+		out = append(out, geminiclient.Content{Role: role, Parts: parts})
+	}
+	if len(system) == 0 {
+		return nil, out
+	}
+	return &geminiclient.Content{Parts: system}, out
+}
+
+// toRawArgs 把工具调用参数转换为 functionCall.args。
+// 它必须是 JSON 对象，参数为空时使用空对象，不是合法JSON对象时包装为 {"arguments": "..."}。
+func toRawArgs(arguments string) json.RawMessage {
+	trimmed := strings.TrimSpace(arguments)
+	if trimmed == "" {
+		return json.RawMessage("{}")
+	}
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	raw, _ := json.Marshal(map[string]string{"arguments": arguments})
+	return raw
+}
+
+// functionResponse 把工具的执行结果转换为 functionResponse.response。
+// 它必须是 JSON 对象，结果本身不是对象时包装为 {"content": result}。
+func functionResponse(content string) any {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	return map[string]any{"content": content}
+}
+
+// toClientTools 把与供应商无关的工具定义转换为 Gemini 的函数声明，所有函数放在同一个 Tool 中。
+func toClientTools(tools []llms.Tool) []geminiclient.Tool {
+	var decls []geminiclient.FunctionDeclaration
+	for _, t := range tools {
+		if t.Function == nil {
+			continue
+		}
+		decls = append(decls, geminiclient.FunctionDeclaration{
+			Name:                 t.Function.Name,
+			Description:          t.Function.Description,
+			ParametersJSONSchema: t.Function.Parameters,
+		})
+	}
+	if len(decls) == 0 {
+		return nil
+	}
+	return []geminiclient.Tool{{FunctionDeclarations: decls}}
+}
+
+// toToolConfig 把 llms 的工具选择转换为 Gemini 的 functionCallingConfig，
+// "required" 对应 "ANY"，指定函数时使用 "ANY" 并限定允许调用的函数名。
+func toToolConfig(choice any) *geminiclient.ToolConfig {
+	var cfg geminiclient.FunctionCallingConfig
+	switch c := choice.(type) {
+	case string:
+		switch c {
+		case llms.ToolChoiceAuto:
+			cfg.Mode = "AUTO"
+		case llms.ToolChoiceNone:
+			cfg.Mode = "NONE"
+		case llms.ToolChoiceRequired:
+			cfg.Mode = "ANY"
+		default:
+			return nil
+		}
+	case *llms.ToolChoice:
+		if c == nil || c.Function == nil {
+			return nil
+		}
+		cfg.Mode = "ANY"
+		cfg.AllowedFunctionNames = []string{c.Function.Name}
+	default:
+		return nil
+	}
+	return &geminiclient.ToolConfig{FunctionCallingConfig: cfg}
+}
+
+// fromClientParts 把响应中的 functionCall 转换为与供应商无关的工具调用。
+// Gemini 不一定返回调用 ID，没有时按顺序生成本地 ID，这些 ID 不会发回给接口，回传结果时通过函数名关联。
+func fromClientParts(parts []geminiclient.Part) []llms.ToolCall {
+	var out []llms.ToolCall
+	for _, p := range parts {
+		if p.FunctionCall == nil {
+			continue
+		}
+		id := p.FunctionCall.ID
+		if id == "" {
+			id = fmt.Sprintf("%s%d", localCallIDPrefix, len(out))
+		}
+		args := string(p.FunctionCall.Args)
+		if args == "" {
+			args = "{}"
+		}
+		out = append(out, llms.ToolCall{
+			ID:   id,
+			Type: llms.ToolTypeFunction,
+			FunctionCall: &llms.FunctionCall{
+				Name:      p.FunctionCall.Name,
+				Arguments: args,
+			},
+		})
+	}
+	return out
+}
+
+// localCallIDPrefix 是 Gemini 没有返回调用 ID 时本地生成的 ID 的前缀，这些 ID 不会发回给接口。
+const localCallIDPrefix = "gemini_local_call_"
+
+// apiCallID 返回需要发回给接口的调用 ID，本地生成的 ID 返回空字符串。
+func apiCallID(id string) string {
+	if strings.HasPrefix(id, localCallIDPrefix) {
+		return ""
+	}
+	return id
+}
+
+// fromClientSafetyRatings 把安全评估结果转换为导出的 SafetyRating。
+func fromClientSafetyRatings(ratings []geminiclient.SafetyRating) []SafetyRating {
+	out := make([]SafetyRating, 0, len(ratings))
+	for _, r := range ratings {
+		out = append(out, SafetyRating{
+			Category:    r.Category,
+			Probability: r.Probability,
+			Blocked:     r.Blocked,
+		})
+	}
+	return out
+}
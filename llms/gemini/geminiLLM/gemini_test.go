@@ -0,0 +1,70 @@
+package geminiLLM
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/gemini/internal/geminiclient"
+)
+
+func TestToolCallIDs(t *testing.T) {
+	// 第一个调用带有 Gemini 返回的 ID，第二个没有，由本地生成。
+	calls := fromClientParts([]geminiclient.Part{
+		{FunctionCall: &geminiclient.FunctionCall{ID: "abc", Name: "get_weather", Args: json.RawMessage(`{"city":"Paris"}`)}},
+		{FunctionCall: &geminiclient.FunctionCall{Name: "get_time"}},
+	})
+	if len(calls) != 2 || calls[0].ID != "abc" || calls[1].ID == "" || calls[1].FunctionCall.Arguments != "{}" {
+		t.Fatalf("tool calls = %+v", calls)
+	}
+
+	messages := []llms.Message{
+		llms.UserMessage("weather and time?"),
+		{Role: llms.RoleAssistant, ToolCalls: calls},
+		{Role: llms.RoleTool, ToolCallID: calls[0].ID, Content: `{"temp":20}`},
+		{Role: llms.RoleTool, ToolCallID: calls[1].ID, Content: "12:00"},
+	}
+	_, contents := toClientContents(messages)
+	if len(contents) != 3 {
+		t.Fatalf("contents = %+v", contents)
+	}
+
+	model := contents[1].Parts
+	if model[0].FunctionCall.ID != "abc" || model[1].FunctionCall.ID != "" {
+		t.Errorf("function call IDs = %q, %q, want abc and empty", model[0].FunctionCall.ID, model[1].FunctionCall.ID)
+	}
+	results := contents[2].Parts
+	if results[0].FunctionResponse.ID != "abc" || results[0].FunctionResponse.Name != "get_weather" {
+		t.Errorf("first result = %+v", results[0].FunctionResponse)
+	}
+	// 本地生成的 ID 不发回，只通过函数名关联。
+	if results[1].FunctionResponse.ID != "" || results[1].FunctionResponse.Name != "get_time" {
+		t.Errorf("second result = %+v", results[1].FunctionResponse)
+	}
+}
+
+func TestToRawArgs(t *testing.T) {
+	tests := map[string]string{
+		"":                 `{}`,
+		"  ":               `{}`,
+		`{"city":"Paris"}`: `{"city":"Paris"}`,
+		`["a"]`:            `{"arguments":"[\"a\"]"}`,
+		`not json`:         `{"arguments":"not json"}`,
+	}
+	for in, want := range tests {
+		if got := string(toRawArgs(in)); got != want {
+			t.Errorf("toRawArgs(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestSkipEmptyMessages(t *testing.T) {
+	_, contents := toClientContents([]llms.Message{
+		llms.UserMessage("hi"),
+		{Role: llms.RoleAssistant},
+		llms.UserMessage("again"),
+	})
+	if len(contents) != 1 || len(contents[0].Parts) != 2 {
+		t.Errorf("contents = %+v", contents)
+	}
+}
@@ -0,0 +1,416 @@
+package geminiclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms/internal/httpretry"
+)
+
+// --- Constants ---
+const (
+	// DefaultChatModel 是Gemini客户端使用的默认模型。
+	DefaultChatModel = "gemini-2.0-flash"
+	// DefaultBaseURL 是Gemini服务的默认基础URL，包含接口版本。
+	DefaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+)
+
+// apiKeyEnvVars 是保存API Key的环境变量名称，按顺序查找。
+var apiKeyEnvVars = []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"}
+
+// --- Errors ---
+// ErrAPIKeyNotFound 表示既没有通过 WithToken 指定API Key，环境变量中也没有找到。
+var ErrAPIKeyNotFound = errors.New("Gemini API Key not found")
+
+// --- Client Structure ---
+
+// Client 表示与Gemini generateContent 接口交互的客户端。
+type Client struct {
+	apikey     string           // Gemini API密钥，通过 x-goog-api-key 请求头发送
+	baseURL    string           // Gemini服务的基准URL
+	httpClient *http.Client     // 用于发送HTTP请求，默认为http.DefaultClient
+	headers    http.Header      // 附加在每个请求上的自定义请求头
+	timeout    time.Duration    // 单次请求的超时时间，为0时不额外设置超时
+	retry      httpretry.Policy // 重试策略
+}
+
+// Option 是用于配置Client的函数选项。
+type Option func(*Client)
+
+// WithToken 直接指定API密钥，优先于环境变量。
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.apikey = token
+	}
+}
+
+// WithBaseURL 指定服务的基础URL（包含接口版本），可用于接入网关或代理。
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader 为每个请求附加一个自定义请求头。
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Add(key, value)
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间，流式请求的超时覆盖读取整个流的过程。
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1表示不重试。
+func WithMaxAttempts(attempts int) Option {
+	return func(c *Client) {
+		c.retry.MaxAttempts = attempts
+	}
+}
+
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+// 实际等待时间在指数增长的上限内随机取值；服务端返回 Retry-After 时以它为准（不超过 max）。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(c *Client) {
+		c.retry.InitialBackoff = initial
+		c.retry.MaxBackoff = max
+	}
+}
+
+// --- Client Constructor ---
+
+// New 创建并返回一个新的Gemini Client实例。
+// API密钥按以下顺序查找：WithToken 选项、GEMINI_API_KEY、GOOGLE_API_KEY 环境变量。
+func New(opts ...Option) (*Client, error) {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		httpClient: http.DefaultClient,
+		retry:      httpretry.DefaultPolicy(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
+	for _, name := range apiKeyEnvVars {
+		if c.apikey != "" {
+			break
+		}
+		c.apikey = strings.TrimSpace(os.Getenv(name))
+	}
+	if c.apikey == "" {
+		return nil, fmt.Errorf("%w (tried: WithToken option, env %s)", ErrAPIKeyNotFound, strings.Join(apiKeyEnvVars, ", "))
+	}
+	return c, nil
+}
+
+// --- Request and Response Payloads ---
+
+// Content 结构体表示对话中的一条消息，Role 为 "user" 或 "model"。
+type Content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []Part `json:"parts"`
+}
+
+// Part 结构体是消息的一个组成部分，每个 Part 只设置其中一个字段。
+type Part struct {
+	Text             string            `json:"text,omitempty"`
+	FunctionCall     *FunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *FunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// FunctionCall 结构体表示模型请求的一次函数调用，Args 是JSON对象。
+type FunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// FunctionResponse 结构体是回传给模型的函数执行结果，Response 必须是JSON对象。
+type FunctionResponse struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Response any    `json:"response"`
+}
+
+// Tool 结构体表示请求中声明的一组函数。
+type Tool struct {
+	FunctionDeclarations []FunctionDeclaration `json:"functionDeclarations"`
+}
+
+// FunctionDeclaration 结构体描述一个可以被模型调用的函数，参数使用JSON Schema描述。
+type FunctionDeclaration struct {
+	Name                 string `json:"name"`
+	Description          string `json:"description,omitempty"`
+	ParametersJSONSchema any    `json:"parametersJsonSchema,omitempty"`
+}
+
+// ToolConfig 结构体对应请求中的toolConfig字段。
+type ToolConfig struct {
+	FunctionCallingConfig FunctionCallingConfig `json:"functionCallingConfig"`
+}
+
+// FunctionCallingConfig 的 Mode 为 "AUTO"、"ANY" 或 "NONE"，
+// 为 "ANY" 时可以通过 AllowedFunctionNames 限定模型能调用的函数。
+type FunctionCallingConfig struct {
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// GenerationConfig 结构体对应请求中的generationConfig字段，为nil的字段不会出现在请求体中。
+type GenerationConfig struct {
+	Temperature        *float64 `json:"temperature,omitempty"`
+	TopP               *float64 `json:"topP,omitempty"`
+	TopK               *int     `json:"topK,omitempty"`
+	MaxOutputTokens    *int     `json:"maxOutputTokens,omitempty"`
+	StopSequences      []string `json:"stopSequences,omitempty"`
+	Seed               *int     `json:"seed,omitempty"`
+	PresencePenalty    *float64 `json:"presencePenalty,omitempty"`
+	FrequencyPenalty   *float64 `json:"frequencyPenalty,omitempty"`
+	ResponseMIMEType   string   `json:"responseMimeType,omitempty"`   // "application/json" 时开启JSON模式
+	ResponseJSONSchema any      `json:"responseJsonSchema,omitempty"` // 约束输出的JSON Schema
+}
+
+// SafetySetting 结构体设置某一类有害内容的拦截阈值，
+// 例如 {"HARM_CATEGORY_HARASSMENT", "BLOCK_ONLY_HIGH"}。
+type SafetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+// GenerateContentRequest 结构体定义了发送到 models/{model}:generateContent 的请求体。
+type GenerateContentRequest struct {
+	Model             string            `json:"-"` // 模型名称，位于URL中
+	Contents          []Content         `json:"contents"`
+	SystemInstruction *Content          `json:"systemInstruction,omitempty"` // 系统提示不在 contents 中
+	GenerationConfig  *GenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings    []SafetySetting   `json:"safetySettings,omitempty"`
+	Tools             []Tool            `json:"tools,omitempty"`
+	ToolConfig        *ToolConfig       `json:"toolConfig,omitempty"`
+
+	// StreamingFunc 不为空时改为请求 streamGenerateContent，每收到一段增量文本就回调一次。
+	// 回调返回错误会中止读取流。
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
+}
+
+// SafetyRating 结构体是某一类有害内容的评估结果。
+type SafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked"`
+}
+
+// Candidate 结构体是模型生成的一个候选回复。
+type Candidate struct {
+	Content       Content        `json:"content"`
+	FinishReason  string         `json:"finishReason"` // "STOP"、"MAX_TOKENS"、"SAFETY"、"RECITATION" 等
+	SafetyRatings []SafetyRating `json:"safetyRatings"`
+	Index         int            `json:"index"`
+}
+
+// PromptFeedback 结构体是对提示本身的安全评估，提示被拦截时 BlockReason 不为空。
+type PromptFeedback struct {
+	BlockReason   string         `json:"blockReason"`
+	SafetyRatings []SafetyRating `json:"safetyRatings"`
+}
+
+// UsageMetadata 结构体表示本次API调用的token使用情况。
+type UsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+// GenerateContentResponse 结构体是 generateContent 返回的响应，流式响应的每个数据块也是这个结构。
+type GenerateContentResponse struct {
+	Candidates     []Candidate     `json:"candidates"`
+	PromptFeedback *PromptFeedback `json:"promptFeedback"`
+	UsageMetadata  UsageMetadata   `json:"usageMetadata"`
+	ModelVersion   string          `json:"modelVersion"`
+	ResponseID     string          `json:"responseId"`
+}
+
+// Text 返回第一个候选回复中所有文本拼接后的内容。
+func (r *GenerateContentResponse) Text() string {
+	if len(r.Candidates) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, p := range r.Candidates[0].Content.Parts {
+		b.WriteString(p.Text)
+	}
+	return b.String()
+}
+
+// --- Public Generate Method ---
+
+// GenerateContent 发送生成请求并返回完整的响应，流式请求返回的是聚合之后的响应。
+// 非200响应统一转换为 *APIError。提示被安全策略拦截时响应中没有候选回复，
+// 原因位于 PromptFeedback 中，由调用方处理。
+func (c *Client) GenerateContent(ctx context.Context, payload *GenerateContentRequest) (*GenerateContentResponse, error) {
+	model := strings.TrimPrefix(payload.Model, "models/")
+	if model == "" {
+		model = DefaultChatModel
+	}
+
+	// 设置了超时时间时，为本次请求派生一个带超时的ctx。
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON payload: %w", err)
+	}
+
+	// 流式请求使用 streamGenerateContent，并通过 alt=sse 要求以SSE格式返回。
+	stream := payload.StreamingFunc != nil
+	url := c.baseURL + "/models/" + model + ":generateContent"
+	if stream {
+		url = c.baseURL + "/models/" + model + ":streamGenerateContent?alt=sse"
+	}
+
+	r, err := httpretry.Do(ctx, c.httpClient, c.retry, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payloadBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-goog-api-key", c.apikey)
+		for key, values := range c.headers {
+			req.Header[key] = values
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, newAPIError(r)
+	}
+
+	if stream {
+		return parseStream(ctx, r.Body, payload.StreamingFunc)
+	}
+
+	var response GenerateContentResponse
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode Gemini API response: %w", err)
+	}
+	return &response, nil
+}
+
+// parseStream 解析 streamGenerateContent 返回的SSE流，每个 data: 行是一个 GenerateContentResponse。
+// 文本和函数调用按顺序累积到第一个候选回复中，结束原因、安全评估和用量以最后一个数据块为准。
+// 连接在收到 finishReason 之前中断时返回错误，而不是把不完整的内容当作成功的响应。
+func parseStream(ctx context.Context, body io.Reader, fn func(ctx context.Context, chunk []byte) error) (*GenerateContentResponse, error) {
+	var (
+		response  GenerateContentResponse
+		candidate = Candidate{Content: Content{Role: "model"}}
+		text      strings.Builder
+		calls     []Part
+	)
+	scanner := bufio.NewScanner(body)
+	// 单行可能较长，放宽默认的64KB限制。
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		// 服务端可能在流的中途返回错误数据块，例如配额耗尽或服务过载。
+		var errPayload errorPayload
+		if err := json.Unmarshal([]byte(data), &errPayload); err == nil && errPayload.Error.Message != "" {
+			return nil, newStreamError(errPayload)
+		}
+
+		var chunk GenerateContentResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode Gemini stream chunk: %w", err)
+		}
+		if chunk.ResponseID != "" {
+			response.ResponseID = chunk.ResponseID
+		}
+		if chunk.ModelVersion != "" {
+			response.ModelVersion = chunk.ModelVersion
+		}
+		if chunk.PromptFeedback != nil {
+			response.PromptFeedback = chunk.PromptFeedback
+		}
+		if chunk.UsageMetadata.TotalTokenCount > 0 {
+			response.UsageMetadata = chunk.UsageMetadata
+		}
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+
+		c := chunk.Candidates[0]
+		if c.FinishReason != "" {
+			candidate.FinishReason = c.FinishReason
+		}
+		if len(c.SafetyRatings) > 0 {
+			candidate.SafetyRatings = c.SafetyRatings
+		}
+		for _, p := range c.Content.Parts {
+			switch {
+			case p.FunctionCall != nil:
+				calls = append(calls, p)
+			case p.Text != "":
+				text.WriteString(p.Text)
+				if err := fn(ctx, []byte(p.Text)); err != nil {
+					return nil, fmt.Errorf("streaming func returned an error: %w", err)
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Gemini stream: %w", err)
+	}
+	// Gemini 没有单独的结束事件，最后一个数据块带有 finishReason；
+	// 提示被拦截时没有候选回复，只有 promptFeedback.blockReason。
+	blocked := response.PromptFeedback != nil && response.PromptFeedback.BlockReason != ""
+	if candidate.FinishReason == "" && !blocked {
+		return nil, errors.New("Gemini stream ended before finishReason")
+	}
+
+	if text.Len() > 0 {
+		candidate.Content.Parts = append(candidate.Content.Parts, Part{Text: text.String()})
+	}
+	candidate.Content.Parts = append(candidate.Content.Parts, calls...)
+	if len(candidate.Content.Parts) > 0 || candidate.FinishReason != "" {
+		response.Candidates = []Candidate{candidate}
+	}
+	return &response, nil
+}
@@ -0,0 +1,67 @@
+package geminiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/internal/httpretry"
+	"github.com/zideajang/langChaingo/llms/internal/httputil"
+)
+
+// providerName 是APIError中使用的供应商名称。
+const providerName = "Gemini"
+
+// APIError 是Gemini接口返回非200响应时的错误类型，与 llms.APIError 相同。
+type APIError = llms.APIError
+
+// errorPayload 是Google API的错误响应体结构：
+// {"error":{"code":400,"message":"...","status":"INVALID_ARGUMENT"}}
+type errorPayload struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+// newAPIError 读取错误响应并构造 APIError，Google 的 status 字段作为错误类型。
+func newAPIError(r *http.Response) *APIError {
+	body := httputil.ReadErrorBody(r.Body)
+	apiErr := &APIError{
+		Provider:   providerName,
+		StatusCode: r.StatusCode,
+		RequestID:  httputil.RequestID(r.Header),
+		Retryable:  httpretry.IsRetryableStatus(r.StatusCode),
+	}
+
+	var payload errorPayload
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error.Message != "" {
+		apiErr.Message = payload.Error.Message
+		apiErr.Type = payload.Error.Status
+		if payload.Error.Code != 0 {
+			apiErr.Code = fmt.Sprint(payload.Error.Code)
+		}
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
+// newStreamError 在流式响应中途收到 {"error":{...}} 数据块时构造 APIError。
+// 此时HTTP状态码已经是200，因此使用错误体中的 code 作为状态码。
+func newStreamError(payload errorPayload) *APIError {
+	apiErr := &APIError{
+		Provider:   providerName,
+		StatusCode: payload.Error.Code,
+		Message:    payload.Error.Message,
+		Type:       payload.Error.Status,
+		Retryable:  httpretry.IsRetryableStatus(payload.Error.Code),
+	}
+	if payload.Error.Code != 0 {
+		apiErr.Code = fmt.Sprint(payload.Error.Code)
+	}
+	return apiErr
+}
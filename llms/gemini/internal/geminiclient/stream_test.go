@@ -0,0 +1,83 @@
+package geminiclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+func collect(chunks *[]string) func(context.Context, []byte) error {
+	return func(_ context.Context, chunk []byte) error {
+		*chunks = append(*chunks, string(chunk))
+		return nil
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	body := `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}],"responseId":"r1"}
+
+data: {"candidates":[{"content":{"role":"model","parts":[{"text":"lo"},{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"totalTokenCount":5}}
+
+`
+	var chunks []string
+	resp, err := parseStream(context.Background(), strings.NewReader(body), collect(&chunks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(chunks, "|"); got != "Hel|lo" {
+		t.Errorf("chunks = %q", got)
+	}
+	if resp.ResponseID != "r1" || resp.UsageMetadata.TotalTokenCount != 5 {
+		t.Errorf("response = %+v", resp)
+	}
+	c := resp.Candidates[0]
+	if c.FinishReason != "STOP" || len(c.Content.Parts) != 2 || c.Content.Parts[0].Text != "Hello" ||
+		c.Content.Parts[1].FunctionCall == nil || c.Content.Parts[1].FunctionCall.Name != "get_weather" {
+		t.Errorf("candidate = %+v", c)
+	}
+}
+
+func TestParseStreamTruncated(t *testing.T) {
+	// 连接在最后一个带 finishReason 的数据块之前中断。
+	body := `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}]}
+
+`
+	var chunks []string
+	if _, err := parseStream(context.Background(), strings.NewReader(body), collect(&chunks)); err == nil {
+		t.Error("truncated stream succeeded, want error")
+	}
+}
+
+func TestParseStreamBlocked(t *testing.T) {
+	body := `data: {"promptFeedback":{"blockReason":"SAFETY"}}
+
+`
+	var chunks []string
+	resp, err := parseStream(context.Background(), strings.NewReader(body), collect(&chunks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Candidates) != 0 || resp.PromptFeedback.BlockReason != "SAFETY" {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestParseStreamError(t *testing.T) {
+	body := `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}]}
+
+data: {"error":{"code":503,"message":"The model is overloaded.","status":"UNAVAILABLE"}}
+
+`
+	var chunks []string
+	_, err := parseStream(context.Background(), strings.NewReader(body), collect(&chunks))
+	var apiErr *llms.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *llms.APIError", err)
+	}
+	if apiErr.StatusCode != 503 || apiErr.Type != "UNAVAILABLE" || !apiErr.Retryable {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+}
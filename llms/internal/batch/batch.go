@@ -0,0 +1,35 @@
+// Package batch 提供并发数受限的批量执行工具，供各供应商的 Generate 使用。
+package batch
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultMaxConcurrency 是批量生成时默认的最大并发请求数。
+const DefaultMaxConcurrency = 8
+
+// Run 对 [0, n) 中的每一个下标调用一次 fn，同时运行的 fn 不超过 maxConcurrency 个，
+// maxConcurrency 小于等于0时不限制并发。Run 会等待所有 fn 返回。
+// ctx 结束后尚未开始的任务仍然会被调用，由 fn 根据 ctx 自行返回错误，
+// 这样每个下标都能得到一个结果。
+func Run(ctx context.Context, n, maxConcurrency int, fn func(ctx context.Context, i int)) {
+	if maxConcurrency <= 0 || maxConcurrency > n {
+		maxConcurrency = n
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(maxConcurrency, 1))
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,163 @@
+// Package httpretry 为各个供应商的客户端提供带指数退避的HTTP重试。
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// --- Defaults ---
+const (
+	// DefaultMaxAttempts 是包含第一次请求在内的默认最大尝试次数。
+	DefaultMaxAttempts = 3
+	// DefaultInitialBackoff 是第一次重试前的基础等待时间。
+	DefaultInitialBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff 是两次尝试之间的最长等待时间，同时也是 Retry-After 的上限。
+	DefaultMaxBackoff = 30 * time.Second
+	// statusOverloaded 是 Anthropic 在服务过载时返回的非标准状态码。
+	statusOverloaded = 529
+	// maxDrainBytes 是重试前最多读取并丢弃的响应体字节数，便于复用连接。
+	maxDrainBytes = 64 * 1024
+)
+
+// Policy 描述重试策略。
+type Policy struct {
+	// MaxAttempts 是包含第一次请求在内的最大尝试次数，小于等于1表示不重试。
+	MaxAttempts int
+	// InitialBackoff 是第一次重试前的基础等待时间，之后每次翻倍。
+	InitialBackoff time.Duration
+	// MaxBackoff 是两次尝试之间的最长等待时间。
+	MaxBackoff time.Duration
+}
+
+// DefaultPolicy 返回默认的重试策略。
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    DefaultMaxAttempts,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+	}
+}
+
+// Do 使用 newRequest 构造请求并发送，遇到可重试的状态码或网络错误时按照策略重试。
+// 每次尝试都会重新调用 newRequest，因此请求体可以被重新读取。
+// 最后一次尝试的响应无论状态码如何都会原样返回，由调用方负责关闭响应体。
+func Do(ctx context.Context, client *http.Client, policy Policy, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	attempts := max(policy.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		last := attempt >= attempts
+		switch {
+		case err != nil:
+			if last || ctx.Err() != nil || !IsRetryableError(err) {
+				return nil, err
+			}
+		case !IsRetryableStatus(resp.StatusCode) || last:
+			return resp, nil
+		}
+
+		wait := Backoff(policy, attempt)
+		if resp != nil {
+			if d, ok := RetryAfter(resp.Header, time.Now()); ok {
+				wait = min(d, maxBackoff(policy))
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// IsRetryableStatus 判断HTTP状态码是否值得重试：
+// 请求超时、限流以及服务端的临时错误（包括Ollama加载模型时返回的500和Anthropic过载时返回的529）。
+func IsRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooEarly,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+		statusOverloaded:
+		return true
+	}
+	return false
+}
+
+// IsRetryableError 判断发送请求时遇到的错误是否值得重试。
+// ctx 被取消或超时不会重试；连接被拒绝、被重置、意外断开以及网络超时会重试。
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// RetryAfter 解析 Retry-After 响应头，支持秒数和HTTP日期两种格式。
+func RetryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// Backoff 返回第 attempt 次尝试失败后的等待时间。
+// 采用 full jitter：在 [0, min(MaxBackoff, InitialBackoff*2^(attempt-1))] 之间随机取值，
+// 避免大量客户端在同一时刻重试。
+func Backoff(policy Policy, attempt int) time.Duration {
+	base := policy.InitialBackoff
+	if base <= 0 {
+		base = DefaultInitialBackoff
+	}
+	ceiling := maxBackoff(policy)
+	d := base
+	for i := 1; i < attempt && d < ceiling; i++ {
+		d *= 2
+	}
+	d = min(d, ceiling)
+	return time.Duration(rand.Int64N(int64(d) + 1))
+}
+
+func maxBackoff(policy Policy) time.Duration {
+	if policy.MaxBackoff <= 0 {
+		return DefaultMaxBackoff
+	}
+	return policy.MaxBackoff
+}
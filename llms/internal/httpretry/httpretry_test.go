@@ -0,0 +1,195 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestBackoff 检查等待时间落在 [0, min(MaxBackoff, InitialBackoff*2^(attempt-1))] 之内。
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		attempt int
+		ceiling time.Duration
+	}{
+		{"first attempt", Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 1, 100 * time.Millisecond},
+		{"doubles", Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 3, 400 * time.Millisecond},
+		{"capped by max", Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 10, time.Second},
+		{"large attempt does not overflow", Policy{InitialBackoff: time.Second, MaxBackoff: time.Minute}, 1000, time.Minute},
+		{"zero policy uses defaults", Policy{}, 1, DefaultInitialBackoff},
+		{"zero max uses default", Policy{InitialBackoff: time.Hour}, 1, DefaultMaxBackoff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				d := Backoff(tt.policy, tt.attempt)
+				if d < 0 || d > tt.ceiling {
+					t.Fatalf("Backoff = %v, want within [0, %v]", d, tt.ceiling)
+				}
+			}
+		})
+	}
+}
+
+// TestRetryAfter 检查 Retry-After 的秒数和 HTTP 日期两种格式。
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"missing", "", 0, false},
+		{"seconds", "7", 7 * time.Second, true},
+		{"zero seconds", "0", 0, true},
+		{"negative seconds", "-3", 0, false},
+		{"http date", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{"http date in the past", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"garbage", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.value != "" {
+				h.Set("Retry-After", tt.value)
+			}
+			got, ok := RetryAfter(h, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	tests := []struct {
+		code int
+		want bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusNotFound, false},
+		{http.StatusRequestTimeout, true},
+		{http.StatusTooEarly, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+		{529, true},
+	}
+	for _, tt := range tests {
+		if got := IsRetryableStatus(tt.code); got != tt.want {
+			t.Errorf("IsRetryableStatus(%d) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, false},
+		{"deadline", fmt.Errorf("post: %w", context.DeadlineExceeded), false},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"network timeout", timeoutError{}, true},
+		{"other", errors.New("tls: bad certificate"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDo 使用本地服务检查重试次数、最后一次响应的返回以及 Retry-After 的上限。
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // 依次返回的状态码，用完之后一直返回最后一个
+		retryAfter   string
+		maxAttempts  int
+		wantStatus   int
+		wantRequests int
+	}{
+		{"success", []int{200}, "", 3, 200, 1},
+		{"retries then succeeds", []int{503, 429, 200}, "", 3, 200, 3},
+		{"returns last retryable response", []int{503}, "", 3, 503, 3},
+		{"does not retry client errors", []int{400, 200}, "", 3, 400, 1},
+		{"single attempt", []int{503, 200}, "", 1, 503, 1},
+		{"retry-after is capped by max backoff", []int{429, 200}, "3600", 2, 200, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[min(requests, len(tt.statuses)-1)]
+				requests++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+			}))
+			defer srv.Close()
+
+			policy := Policy{MaxAttempts: tt.maxAttempts, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			resp, err := Do(ctx, srv.Client(), policy, func(ctx context.Context) (*http.Request, error) {
+				return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+			})
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus || requests != tt.wantRequests {
+				t.Errorf("status %d after %d requests, want %d after %d", resp.StatusCode, requests, tt.wantStatus, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// TestDoCanceled 检查等待重试期间 ctx 结束时立即返回。
+func TestDoCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	policy := Policy{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+	start := time.Now()
+	_, err := Do(ctx, srv.Client(), policy, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Do returned after %v, want prompt return", elapsed)
+	}
+}
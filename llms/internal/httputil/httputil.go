@@ -0,0 +1,34 @@
+// Package httputil 提供各供应商客户端处理HTTP响应时共用的小工具。
+package httputil
+
+import (
+	"io"
+	"net/http"
+)
+
+// maxErrorBodyBytes 是读取错误响应体的上限，避免异常响应占用过多内存。
+const maxErrorBodyBytes = 64 * 1024
+
+// requestIDHeaders 是常见供应商用于返回请求ID的响应头，按顺序查找。
+var requestIDHeaders = []string{
+	"X-Request-Id",
+	"Request-Id",
+	"X-Ds-Trace-Id",
+	"Cf-Ray",
+}
+
+// RequestID 从响应头中取出请求ID，找不到时返回空字符串。
+func RequestID(h http.Header) string {
+	for _, key := range requestIDHeaders {
+		if v := h.Get(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ReadErrorBody 读取错误响应体，最多读取64KB。
+func ReadErrorBody(r io.Reader) []byte {
+	body, _ := io.ReadAll(io.LimitReader(r, maxErrorBodyBytes))
+	return body
+}
@@ -0,0 +1,574 @@
+// Package openaiclient 实现 OpenAI chat completions 接口的请求格式，
+// 供 DeepSeek、OpenAI 以及各种 OpenAI 兼容服务（vLLM、LM Studio、llama.cpp server 等）共用。
+// 各供应商的差异（认证请求头、路径、查询参数等）通过 Option 配置。
+package openaiclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms/internal/httpretry"
+)
+
+// --- Constants ---
+const (
+	// DefaultChatPath 是聊天补全接口的默认路由。
+	DefaultChatPath = "/chat/completions"
+	// DefaultEmbeddingsPath 是向量接口的默认路由。
+	DefaultEmbeddingsPath = "/embeddings"
+	// DefaultAuthHeader 是默认的认证请求头。
+	DefaultAuthHeader = "Authorization"
+	// DefaultAuthPrefix 是默认认证请求头的值前缀。
+	DefaultAuthPrefix = "Bearer "
+	// defaultProviderName 是未设置 WithProviderName 时在错误信息中使用的供应商名称。
+	defaultProviderName = "OpenAI-compatible"
+)
+
+// --- Errors ---
+// ErrEmptyResponse 表示模型返回的内容为空。
+var ErrEmptyResponse = errors.New("empty response from model")
+
+// --- Client Structure ---
+
+// Client 表示与 OpenAI 兼容接口交互的客户端。
+type Client struct {
+	apikey       string           // API密钥，为空时不发送认证请求头
+	baseURL      string           // 服务的基准URL
+	chatPath     string           // 聊天补全接口的路由
+	embedPath    string           // 向量接口的路由
+	query        url.Values       // 附加在每个请求URL上的查询参数
+	authHeader   string           // 认证请求头的名称
+	authPrefix   string           // 认证请求头的值前缀
+	providerName string           // 错误信息和 APIError 中使用的供应商名称
+	defaultModel string           // 请求未指定模型时使用的模型
+	httpClient   *http.Client     // 用于发送HTTP请求，默认为http.DefaultClient
+	headers      http.Header      // 附加在每个请求上的自定义请求头
+	timeout      time.Duration    // 单次请求的超时时间，为0时不额外设置超时
+	retry        httpretry.Policy // 重试策略
+	streamUsage  bool             // 流式请求时是否发送 stream_options.include_usage
+}
+
+// Option 是用于配置Client的函数选项。
+type Option func(*Client)
+
+// WithAPIKey 设置API密钥。
+func WithAPIKey(apikey string) Option {
+	return func(c *Client) {
+		c.apikey = apikey
+	}
+}
+
+// WithBaseURL 指定服务的基础URL。
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithChatPath 指定聊天补全接口的路由，默认为 DefaultChatPath。
+func WithChatPath(path string) Option {
+	return func(c *Client) {
+		c.chatPath = path
+	}
+}
+
+// WithEmbeddingsPath 指定向量接口的路由，默认为 DefaultEmbeddingsPath。
+func WithEmbeddingsPath(path string) Option {
+	return func(c *Client) {
+		c.embedPath = path
+	}
+}
+
+// WithQueryParam 为每个请求的URL附加一个查询参数，例如 Azure OpenAI 的 api-version。
+func WithQueryParam(key, value string) Option {
+	return func(c *Client) {
+		if c.query == nil {
+			c.query = url.Values{}
+		}
+		c.query.Add(key, value)
+	}
+}
+
+// WithAuthHeader 设置认证请求头的名称和值前缀，例如 ("api-key", "") 或 ("Authorization", "Bearer ")。
+func WithAuthHeader(name, prefix string) Option {
+	return func(c *Client) {
+		c.authHeader = name
+		c.authPrefix = prefix
+	}
+}
+
+// WithProviderName 设置错误信息和 APIError 中使用的供应商名称。
+func WithProviderName(name string) Option {
+	return func(c *Client) {
+		c.providerName = name
+	}
+}
+
+// WithDefaultModel 设置请求未指定模型时使用的模型。
+func WithDefaultModel(model string) Option {
+	return func(c *Client) {
+		c.defaultModel = model
+	}
+}
+
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader 为每个请求附加一个自定义请求头。
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Add(key, value)
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间，流式请求的超时覆盖读取整个流的过程。
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1表示不重试。
+func WithMaxAttempts(attempts int) Option {
+	return func(c *Client) {
+		c.retry.MaxAttempts = attempts
+	}
+}
+
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+// 实际等待时间在指数增长的上限内随机取值；服务端返回 Retry-After 时以它为准（不超过 max）。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(c *Client) {
+		c.retry.InitialBackoff = initial
+		c.retry.MaxBackoff = max
+	}
+}
+
+// WithStreamUsage 设置流式请求是否发送 stream_options: {"include_usage": true}，
+// 让最后一个数据块携带 token 用量，默认不发送；不认识这个字段的兼容服务可能会拒绝请求。
+func WithStreamUsage(enabled bool) Option {
+	return func(c *Client) {
+		c.streamUsage = enabled
+	}
+}
+
+// --- Client Constructor ---
+
+// New 创建并返回一个新的 Client 实例，必须通过 WithBaseURL 指定服务地址。
+func New(opts ...Option) (*Client, error) {
+	c := &Client{
+		chatPath:     DefaultChatPath,
+		embedPath:    DefaultEmbeddingsPath,
+		authHeader:   DefaultAuthHeader,
+		authPrefix:   DefaultAuthPrefix,
+		providerName: defaultProviderName,
+		httpClient:   http.DefaultClient,
+		retry:        httpretry.DefaultPolicy(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.baseURL == "" {
+		return nil, errors.New("base URL is required")
+	}
+	return c, nil
+}
+
+// ProviderName 返回错误信息中使用的供应商名称。
+func (c *Client) ProviderName() string {
+	return c.providerName
+}
+
+// --- Request and Response Payloads (OpenAI-compatible) ---
+
+// Message 结构体表示聊天中的一条消息。
+type Message struct {
+	Role    string `json:"role"`    // 消息发送者的角色 (e.g., "user", "system", "assistant", "tool")
+	Content string `json:"content"` // 消息的文本内容
+
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // assistant消息中模型请求的工具调用
+	ToolCallID string     `json:"tool_call_id,omitempty"` // tool消息对应的调用ID
+}
+
+// Tool 结构体表示请求中声明的一个工具。
+type Tool struct {
+	Type     string             `json:"type"` // 目前只支持 "function"
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition 结构体描述一个可以被模型调用的函数。
+type FunctionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"` // 参数的JSON Schema
+}
+
+// ToolCall 结构体表示模型请求的一次工具调用。
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall 结构体表示一次函数调用，Arguments 是JSON编码的参数。
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ChatRequest 结构体定义了发送到 /chat/completions 的聊天请求体。
+type ChatRequest struct {
+	Model    string    `json:"model,omitempty"` // 要使用的模型名称，部分本地服务可以省略
+	Messages []Message `json:"messages"`        // 聊天消息列表
+	Stream   bool      `json:"stream"`          // 是否以流式方式获取响应 (false表示获取完整响应)
+
+	// 以下为OpenAI风格的顶层采样参数，为nil时不会出现在请求体中。
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	MaxTokens        *int     `json:"max_tokens,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+
+	// Tools 是模型可以调用的工具，ToolChoice 为 "auto"、"none"、"required" 或指定函数的对象。
+	Tools      []Tool `json:"tools,omitempty"`
+	ToolChoice any    `json:"tool_choice,omitempty"`
+
+	// ResponseFormat 为 {"type":"json_object"} 时开启JSON模式。
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+
+	// StreamOptions 只在开启了 WithStreamUsage 的流式请求中发送，用于让最后一个数据块携带usage。
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+
+	// StreamingFunc 不为空时以流式方式请求，每收到一段增量内容就回调一次。
+	// 回调返回错误会中止读取流。
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
+}
+
+// StreamOptions 对应OpenAI兼容接口中的stream_options字段。
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// ResponseFormat 结构体对应请求中的response_format字段。
+// Type 为 "json_object" 时开启JSON模式，为 "json_schema" 时按 JSONSchema 约束输出。
+// DeepSeek 以及大部分兼容服务只支持 "text" 和 "json_object"。
+type ResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *JSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+// JSONSchemaFormat 对应response_format中的json_schema字段。
+type JSONSchemaFormat struct {
+	Name   string `json:"name"`
+	Schema any    `json:"schema"`
+	Strict bool   `json:"strict,omitempty"`
+}
+
+// ChatChoice 结构体表示聊天补全的一个选项。
+type ChatChoice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+// Usage 结构体表示本次API调用的token使用情况。
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatResponsePayload 结构体用于解析 /chat/completions 返回的完整JSON响应。
+type ChatResponsePayload struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   Usage        `json:"usage"`
+}
+
+// streamChunk 结构体用于解析流式响应中每一个 data: 行的JSON数据。
+// 与完整响应不同，增量内容位于choices[].delta中。
+type streamChunk struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []streamChoice `json:"choices"`
+	Usage   *Usage         `json:"usage"`
+}
+
+type streamChoice struct {
+	Index        int         `json:"index"`
+	Delta        streamDelta `json:"delta"`
+	FinishReason *string     `json:"finish_reason"`
+}
+
+// streamDelta 是流式响应中的增量消息，工具调用的参数会被拆分到多个数据块中，
+// 通过index关联到同一个调用。
+type streamDelta struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []streamToolCall `json:"tool_calls"`
+}
+
+type streamToolCall struct {
+	Index    int          `json:"index"`
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// ChatResponse 结构体是客户端向外部暴露的聊天响应。
+// 除了LLM生成的内容，还保留了响应ID、结束原因和token使用情况。
+type ChatResponse struct {
+	Content      string // LLM生成的内容
+	ID           string
+	Object       string
+	Created      int64
+	Model        string
+	FinishReason string // 结束原因，例如 "stop"、"length"、"tool_calls"
+	Usage        Usage
+	ToolCalls    []ToolCall // 模型请求的工具调用
+}
+
+// --- Internal HTTP Request Method ---
+
+// doChat 是一个内部方法，负责向 /chat/completions 发送实际的HTTP聊天请求。
+// ctx 用于管理请求的生命周期和超时。
+// payload 包含要发送的聊天请求数据。
+func (c *Client) doChat(ctx context.Context, payload *ChatRequest) (*ChatResponsePayload, error) {
+	// 如果没有指定模型，则使用默认模型。
+	if payload.Model == "" {
+		payload.Model = c.defaultModel
+	}
+
+	// 设置了超时时间时，为本次请求派生一个带超时的ctx。
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	// 只有设置了 StreamingFunc 时才使用流式传输，否则期望一次性返回完整响应。
+	payload.Stream = payload.StreamingFunc != nil
+	if payload.Stream && c.streamUsage {
+		payload.StreamOptions = &StreamOptions{IncludeUsage: true}
+	} else {
+		payload.StreamOptions = nil
+	}
+
+	// 将请求体转换为JSON字节数组。
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON payload: %w", err)
+	}
+
+	r, err := c.post(ctx, c.chatPath, payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close() // 确保响应体在使用后关闭
+
+	// 流式响应需要按SSE格式逐行解析。
+	if payload.Stream {
+		return parseStream(ctx, r.Body, payload.StreamingFunc)
+	}
+
+	// 声明一个变量来存储解析后的API响应。
+	var response ChatResponsePayload
+	// 将HTTP响应体中的JSON数据解码到结构体中。
+	err = json.NewDecoder(r.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s API response: %w", c.providerName, err)
+	}
+	return &response, nil
+}
+
+// post 向 path 发送JSON请求体，遇到限流、服务端临时错误或网络错误时按照重试策略重试。
+// 非200响应统一转换为 *APIError，便于调用方按类别处理；成功时由调用方关闭响应体。
+func (c *Client) post(ctx context.Context, path string, payload []byte) (*http.Response, error) {
+	url := c.url(path)
+
+	// 每次尝试都重新创建请求，以便重新读取请求体。
+	r, err := httpretry.Do(ctx, c.httpClient, c.retry, func(ctx context.Context) (*http.Request, error) {
+		// 创建新的HTTP POST请求。
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
+
+		// 设置请求头，指定内容类型为JSON和授权信息。
+		req.Header.Set("Content-Type", "application/json")
+		if c.apikey != "" {
+			req.Header.Set(c.authHeader, c.authPrefix+c.apikey)
+		}
+		for key, values := range c.headers {
+			req.Header[key] = values
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	if r.StatusCode != http.StatusOK {
+		defer r.Body.Close()
+		return nil, newAPIError(c.providerName, r)
+	}
+	return r, nil
+}
+
+// url 返回 path 对应的完整URL，包括通过 WithQueryParam 设置的查询参数。
+func (c *Client) url(path string) string {
+	u := c.baseURL + path
+	if len(c.query) > 0 {
+		u += "?" + c.query.Encode()
+	}
+	return u
+}
+
+// parseStream 解析 /chat/completions 返回的SSE流。
+// 每个事件以 "data: " 开头，内容为streamChunk，流以 "data: [DONE]" 结束。
+// 返回值是把所有增量内容聚合之后的完整响应。
+func parseStream(ctx context.Context, body io.Reader, fn func(ctx context.Context, chunk []byte) error) (*ChatResponsePayload, error) {
+	var (
+		response  ChatResponsePayload
+		choice    = ChatChoice{Message: Message{Role: "assistant"}}
+		content   strings.Builder
+		toolCalls = map[int]*ToolCall{}
+		done      bool
+	)
+	scanner := bufio.NewScanner(body)
+	// 单行可能较长，放宽默认的64KB限制。
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// 跳过空行以及 ": keep-alive" 之类的注释行。
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		// 部分数据块可能省略这些字段，只保留非空值。
+		if chunk.ID != "" {
+			response.ID = chunk.ID
+		}
+		if chunk.Created != 0 {
+			response.Created = chunk.Created
+		}
+		if chunk.Model != "" {
+			response.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			response.Usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		delta := chunk.Choices[0]
+		if delta.FinishReason != nil {
+			choice.FinishReason = *delta.FinishReason
+		}
+		if delta.Delta.Content != "" {
+			content.WriteString(delta.Delta.Content)
+			if err := fn(ctx, []byte(delta.Delta.Content)); err != nil {
+				return nil, fmt.Errorf("streaming func returned an error: %w", err)
+			}
+		}
+		// 按index合并工具调用：第一个数据块带有id和函数名，之后的数据块只追加参数片段。
+		for _, tc := range delta.Delta.ToolCalls {
+			if tc.Index < 0 {
+				return nil, fmt.Errorf("failed to decode stream chunk: invalid tool call index %d", tc.Index)
+			}
+			call, ok := toolCalls[tc.Index]
+			if !ok {
+				call = &ToolCall{Type: "function"}
+				toolCalls[tc.Index] = call
+			}
+			if tc.ID != "" {
+				call.ID = tc.ID
+			}
+			if tc.Type != "" {
+				call.Type = tc.Type
+			}
+			call.Function.Name += tc.Function.Name
+			call.Function.Arguments += tc.Function.Arguments
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if !done {
+		return nil, errors.New("stream ended before [DONE]")
+	}
+
+	response.Object = "chat.completion"
+	choice.Message.Content = content.String()
+	// 按index排序，丢弃没有id或函数名的不完整调用。
+	for _, index := range slices.Sorted(maps.Keys(toolCalls)) {
+		if call := toolCalls[index]; call.ID != "" && call.Function.Name != "" {
+			choice.Message.ToolCalls = append(choice.Message.ToolCalls, *call)
+		}
+	}
+	response.Choices = []ChatChoice{choice}
+	return &response, nil
+}
+
+// --- Public Chat Method ---
+
+// Chat 方法是客户端的公共入口点，用于发送聊天请求。
+// 它调用内部的doChat方法并处理返回的响应。
+func (c *Client) Chat(ctx context.Context, r *ChatRequest) (*ChatResponse, error) {
+	resp, err := c.doChat(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	// 检查响应是否包含有效的消息内容或工具调用。
+	if len(resp.Choices) == 0 ||
+		(resp.Choices[0].Message.Content == "" && len(resp.Choices[0].Message.ToolCalls) == 0) {
+		return nil, ErrEmptyResponse
+	}
+	return &ChatResponse{
+		Content:      resp.Choices[0].Message.Content,
+		ToolCalls:    resp.Choices[0].Message.ToolCalls,
+		ID:           resp.ID,
+		Object:       resp.Object,
+		Created:      resp.Created,
+		Model:        resp.Model,
+		FinishReason: resp.Choices[0].FinishReason,
+		Usage:        resp.Usage,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestStreamUsageOption 检查 stream_options 只在开启 WithStreamUsage 的流式请求中发送。
+func TestStreamUsageOption(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		streaming bool
+		want      bool
+	}{
+		{"default streaming", nil, true, false},
+		{"enabled streaming", []Option{WithStreamUsage(true)}, true, true},
+		{"disabled streaming", []Option{WithStreamUsage(true), WithStreamUsage(false)}, true, false},
+		{"enabled non-streaming", []Option{WithStreamUsage(true)}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(b, &body); err != nil {
+					t.Errorf("invalid request body: %v", err)
+				}
+				if tt.streaming {
+					w.Header().Set("Content-Type", "text/event-stream")
+					io.WriteString(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+					return
+				}
+				io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+			}))
+			defer srv.Close()
+
+			c, err := New(append([]Option{WithBaseURL(srv.URL)}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &ChatRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}}
+			if tt.streaming {
+				req.StreamingFunc = discard
+			}
+			if _, err := c.Chat(context.Background(), req); err != nil {
+				t.Fatalf("Chat: %v", err)
+			}
+			_, got := body["stream_options"]
+			if got != tt.want {
+				t.Errorf("stream_options sent = %v, want %v (body %v)", got, tt.want, body)
+			}
+		})
+	}
+}
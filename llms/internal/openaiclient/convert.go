@@ -0,0 +1,131 @@
+package openaiclient
+
+import (
+	"time"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// NewChatRequest 根据与供应商无关的消息和调用选项构建聊天请求。
+// supportsJSONSchema 为 true 时 llms.WithJSONSchema 以 json_schema 格式发送，
+// 否则退化为 json_object 模式。top_k 不属于 OpenAI 接口，会被忽略。
+func NewChatRequest(model string, messages []llms.Message, opts *llms.CallOptions, supportsJSONSchema bool) *ChatRequest {
+	req := &ChatRequest{
+		Model:         model,
+		Messages:      toMessages(messages),
+		Stream:        opts.StreamingFunc != nil, // 只有设置了回调才使用流式响应
+		StreamingFunc: opts.StreamingFunc,
+
+		Temperature:      opts.Temperature,
+		TopP:             opts.TopP,
+		MaxTokens:        opts.MaxTokens,
+		Stop:             opts.StopWords,
+		Seed:             opts.Seed,
+		PresencePenalty:  opts.PresencePenalty,
+		FrequencyPenalty: opts.FrequencyPenalty,
+
+		Tools:      toTools(opts.Tools),
+		ToolChoice: opts.ToolChoice,
+	}
+	switch {
+	case opts.JSONSchema != nil && supportsJSONSchema:
+		req.ResponseFormat = &ResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &JSONSchemaFormat{Name: "output", Schema: opts.JSONSchema},
+		}
+	case opts.JSONMode:
+		req.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	}
+	return req
+}
+
+// ContentResponse 把响应转换为与供应商无关的 llms.ContentResponse，latency 是客户端测量的延迟。
+// OpenAI 兼容接口不上报服务端耗时，Timings 中只有 Latency。
+func (r *ChatResponse) ContentResponse(latency time.Duration) *llms.ContentResponse {
+	return &llms.ContentResponse{
+		Content:      r.Content,
+		Model:        r.Model,
+		ID:           r.ID,
+		FinishReason: r.FinishReason,
+		ToolCalls:    fromToolCalls(r.ToolCalls),
+		Usage: llms.Usage{
+			PromptTokens:     r.Usage.PromptTokens,
+			CompletionTokens: r.Usage.CompletionTokens,
+			TotalTokens:      r.Usage.TotalTokens,
+		},
+		Timings: llms.Timings{
+			Latency: latency,
+		},
+		GenerationInfo: map[string]any{
+			"id":                r.ID,
+			"object":            r.Object,
+			"created":           r.Created,
+			"finish_reason":     r.FinishReason,
+			"prompt_tokens":     r.Usage.PromptTokens,
+			"completion_tokens": r.Usage.CompletionTokens,
+			"total_tokens":      r.Usage.TotalTokens,
+		},
+	}
+}
+
+// toMessages 把与供应商无关的消息转换为请求中的消息结构。
+func toMessages(messages []llms.Message) []Message {
+	out := make([]Message, 0, len(messages))
+	for _, m := range messages {
+		msg := Message{
+			Role:       string(m.Role),
+			Content:    m.Content,
+			ToolCallID: m.ToolCallID,
+		}
+		for _, tc := range m.ToolCalls {
+			if tc.FunctionCall == nil {
+				continue
+			}
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+				ID:   tc.ID,
+				Type: llms.ToolTypeFunction,
+				Function: FunctionCall{
+					Name:      tc.FunctionCall.Name,
+					Arguments: tc.FunctionCall.Arguments,
+				},
+			})
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+// toTools 把与供应商无关的工具定义转换为请求中的工具结构。
+func toTools(tools []llms.Tool) []Tool {
+	var out []Tool
+	for _, t := range tools {
+		if t.Function == nil {
+			continue
+		}
+		out = append(out, Tool{
+			Type: llms.ToolTypeFunction,
+			Function: FunctionDefinition{
+				Name:        t.Function.Name,
+				Description: t.Function.Description,
+				Parameters:  t.Function.Parameters,
+			},
+		})
+	}
+	return out
+}
+
+// fromToolCalls 把响应中的工具调用转换为与供应商无关的结构。
+func fromToolCalls(calls []ToolCall) []llms.ToolCall {
+	var out []llms.ToolCall
+	for _, tc := range calls {
+		out = append(out, llms.ToolCall{
+			ID:   tc.ID,
+			Type: tc.Type,
+			FunctionCall: &llms.FunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: tc.Function.Arguments,
+			},
+		})
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// EmbeddingRequest 结构体定义了发送到 /embeddings 的请求体，一次可以为多段文本生成向量。
+type EmbeddingRequest struct {
+	Model      string   `json:"model,omitempty"`      // 用于生成向量的模型名称
+	Input      []string `json:"input"`                // 需要生成向量的文本
+	Dimensions *int     `json:"dimensions,omitempty"` // 输出向量的维度，只有部分模型支持
+}
+
+// Embedding 结构体是响应中的一个向量，Index 对应请求中 Input 的下标。
+type Embedding struct {
+	Index     int       `json:"index"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// EmbeddingResponse 结构体是 /embeddings 返回的响应。
+type EmbeddingResponse struct {
+	Object string      `json:"object"`
+	Model  string      `json:"model"`
+	Data   []Embedding `json:"data"`
+	Usage  Usage       `json:"usage"`
+}
+
+// CreateEmbeddings 为请求中的每一段文本生成向量，与聊天请求共用基础URL、HTTP客户端、请求头和重试策略。
+// 返回的 Data 按 Index 排序，与 Input 一一对应。
+func (c *Client) CreateEmbeddings(ctx context.Context, payload *EmbeddingRequest) (*EmbeddingResponse, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON payload: %w", err)
+	}
+	r, err := c.post(ctx, c.embedPath, payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	var response EmbeddingResponse
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode %s embeddings response: %w", c.providerName, err)
+	}
+	if len(response.Data) != len(payload.Input) {
+		return nil, fmt.Errorf("%w: got %d embeddings for %d inputs", ErrEmptyResponse, len(response.Data), len(payload.Input))
+	}
+	sort.Slice(response.Data, func(i, j int) bool {
+		return response.Data[i].Index < response.Data[j].Index
+	})
+	return &response, nil
+}
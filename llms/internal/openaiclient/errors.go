@@ -0,0 +1,49 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/internal/httpretry"
+	"github.com/zideajang/langChaingo/llms/internal/httputil"
+)
+
+// APIError 是接口返回非200响应时的错误类型，与 llms.APIError 相同，
+// 可以通过 errors.As 取出，或者通过 errors.Is 与 llms 包中的哨兵错误比较。
+type APIError = llms.APIError
+
+// errorPayload 是OpenAI兼容接口的错误响应体结构：
+// {"error":{"message":"...","type":"...","code":"..."}}
+type errorPayload struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    any    `json:"code"` // 有的实现返回字符串，有的返回数字
+	} `json:"error"`
+}
+
+// newAPIError 读取错误响应并构造 APIError，provider 是 APIError 中的供应商名称。
+func newAPIError(provider string, r *http.Response) *APIError {
+	body := httputil.ReadErrorBody(r.Body)
+	apiErr := &APIError{
+		Provider:   provider,
+		StatusCode: r.StatusCode,
+		RequestID:  httputil.RequestID(r.Header),
+		Retryable:  httpretry.IsRetryableStatus(r.StatusCode),
+	}
+
+	var payload errorPayload
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error.Message != "" {
+		apiErr.Message = payload.Error.Message
+		apiErr.Type = payload.Error.Type
+		if payload.Error.Code != nil {
+			apiErr.Code = fmt.Sprint(payload.Error.Code)
+		}
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
@@ -0,0 +1,140 @@
+package openaiclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func discard(context.Context, []byte) error { return nil }
+
+func TestParseStreamToolCalls(t *testing.T) {
+	body := `data: {"id":"c1","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}},{"index":1,"id":"call_b","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+`
+	resp, err := parseStream(context.Background(), strings.NewReader(body), discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := resp.Choices[0].Message.ToolCalls
+	if len(calls) != 2 {
+		t.Fatalf("tool calls = %+v", calls)
+	}
+	if calls[0].ID != "call_a" || calls[0].Function.Name != "get_weather" || calls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("first call = %+v", calls[0])
+	}
+	if calls[1].ID != "call_b" || calls[1].Function.Name != "get_time" {
+		t.Errorf("second call = %+v", calls[1])
+	}
+	if resp.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("finish reason = %q", resp.Choices[0].FinishReason)
+	}
+}
+
+func TestParseStreamToolCallGaps(t *testing.T) {
+	// index 不连续时不会补出空的调用，没有 id 或函数名的片段也会被丢弃。
+	body := `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":2,"id":"call_c","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":5,"function":{"arguments":"{}"}}]},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+`
+	resp, err := parseStream(context.Background(), strings.NewReader(body), discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := resp.Choices[0].Message.ToolCalls
+	if len(calls) != 1 || calls[0].ID != "call_c" {
+		t.Errorf("tool calls = %+v, want only call_c", calls)
+	}
+}
+
+func TestParseStreamNegativeToolCallIndex(t *testing.T) {
+	body := `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":-1,"id":"call_a","function":{"name":"f"}}]}}]}
+
+data: [DONE]
+`
+	if _, err := parseStream(context.Background(), strings.NewReader(body), discard); err == nil {
+		t.Error("negative tool call index succeeded, want error")
+	}
+}
+
+func TestParseStreamDone(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantContent string
+		wantUsage   int
+		wantErr     string
+	}{
+		{
+			name: "content, usage and done",
+			body: `: keep-alive
+
+data: {"id":"c1","model":"m","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}
+
+data: {"id":"c1","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}
+
+data: [DONE]
+`,
+			wantContent: "Hello",
+			wantUsage:   5,
+		},
+		{
+			name:        "no space after data",
+			body:        "data:{\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\ndata:[DONE]\n",
+			wantContent: "hi",
+		},
+		{
+			name:        "lines after done are ignored",
+			body:        "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\ndata: {not json}\n",
+			wantContent: "hi",
+		},
+		{
+			name:    "truncated before done",
+			body:    "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n",
+			wantErr: "stream ended before [DONE]",
+		},
+		{
+			name:    "empty body",
+			body:    "",
+			wantErr: "stream ended before [DONE]",
+		},
+		{
+			name:    "invalid json",
+			body:    "data: {not json}\n\ndata: [DONE]\n",
+			wantErr: "failed to decode stream chunk",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var streamed strings.Builder
+			resp, err := parseStream(context.Background(), strings.NewReader(tt.body), func(ctx context.Context, chunk []byte) error {
+				streamed.Write(chunk)
+				return nil
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseStream error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStream: %v", err)
+			}
+			if got := resp.Choices[0].Message.Content; got != tt.wantContent || streamed.String() != tt.wantContent {
+				t.Errorf("content = %q, streamed %q, want %q", got, streamed.String(), tt.wantContent)
+			}
+			if resp.Usage.TotalTokens != tt.wantUsage {
+				t.Errorf("usage = %+v, want total %d", resp.Usage, tt.wantUsage)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Package ratelimit 提供按每分钟请求数和每分钟token数限制调用频率的客户端限流器。
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter 是由两个令牌桶组成的限流器：一个限制请求数，一个限制token数。
+// 两个限制都以分钟为单位，桶的容量等于每分钟的配额，令牌按固定速率连续补充。
+type Limiter struct {
+	mu       sync.Mutex
+	requests *bucket // 为nil时不限制请求数
+	tokens   *bucket // 为nil时不限制token数
+}
+
+// New 创建一个限流器，requestsPerMinute 或 tokensPerMinute 小于等于0时表示不限制该项。
+// 两项都不限制时返回nil，nil的Limiter可以安全地调用所有方法。
+func New(requestsPerMinute, tokensPerMinute int) *Limiter {
+	if requestsPerMinute <= 0 && tokensPerMinute <= 0 {
+		return nil
+	}
+	now := time.Now()
+	return &Limiter{
+		requests: newBucket(requestsPerMinute, now),
+		tokens:   newBucket(tokensPerMinute, now),
+	}
+}
+
+// Wait 阻塞直到可以发出一个预计消耗 tokens 个token的请求，或者 ctx 结束。
+// 预计的token数超过每分钟配额时按配额计算，避免永远等待。
+func (l *Limiter) Wait(ctx context.Context, tokens int) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.requests.refill(now)
+		l.tokens.refill(now)
+		need := l.tokens.clamp(float64(tokens))
+		wait := max(l.requests.waitFor(1), l.tokens.waitFor(need))
+		if wait <= 0 {
+			l.requests.take(1)
+			l.tokens.take(need)
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Adjust 在拿到实际用量后修正token桶：delta 为实际用量减去预计用量，
+// 正数会继续扣除配额，负数会返还多扣的配额。
+func (l *Limiter) Adjust(delta int) {
+	if l == nil || l.tokens == nil || delta == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens.refill(time.Now())
+	l.tokens.take(float64(delta))
+}
+
+// bucket 是一个连续补充的令牌桶，available 可以为负数，表示已经透支的配额。
+type bucket struct {
+	capacity  float64
+	available float64
+	perSecond float64
+	last      time.Time
+}
+
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		perSecond: float64(perMinute) / 60,
+		last:      now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b == nil {
+		return
+	}
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.available = min(b.capacity, b.available+elapsed*b.perSecond)
+}
+
+func (b *bucket) clamp(n float64) float64 {
+	if b == nil {
+		return 0
+	}
+	return min(n, b.capacity)
+}
+
+// waitFor 返回桶中积累到 n 个令牌还需要等待的时间。
+func (b *bucket) waitFor(n float64) time.Duration {
+	if b == nil || b.available >= n {
+		return 0
+	}
+	return time.Duration((n - b.available) / b.perSecond * float64(time.Second))
+}
+
+func (b *bucket) take(n float64) {
+	if b == nil {
+		return
+	}
+	b.available -= n
+}
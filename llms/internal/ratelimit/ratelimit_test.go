@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestBucket 使用固定的时间检查令牌桶的补充、透支和等待时间。
+func TestBucket(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		perMinute int
+		take      float64       // 在 start 时刻取走的令牌数
+		elapsed   time.Duration // 取走之后经过的时间
+		need      float64
+		wantAvail float64
+		wantWait  time.Duration
+	}{
+		{"full bucket", 60, 0, 0, 1, 60, 0},
+		{"empty bucket waits one token", 60, 60, 0, 1, 0, time.Second},
+		{"refills over time", 60, 60, 30 * time.Second, 1, 30, 0},
+		{"refill is capped at capacity", 60, 10, time.Hour, 1, 60, 0},
+		{"overdraft waits longer", 60, 90, 0, 1, -30, 31 * time.Second},
+		{"partial refill", 120, 120, 250 * time.Millisecond, 2, 0.5, 750 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBucket(tt.perMinute, start)
+			b.take(tt.take)
+			b.refill(start.Add(tt.elapsed))
+			if b.available != tt.wantAvail {
+				t.Errorf("available = %v, want %v", b.available, tt.wantAvail)
+			}
+			if got := b.waitFor(tt.need); got != tt.wantWait {
+				t.Errorf("waitFor(%v) = %v, want %v", tt.need, got, tt.wantWait)
+			}
+		})
+	}
+}
+
+// TestNilBucket 检查不限制的一项（nil 桶）从不需要等待。
+func TestNilBucket(t *testing.T) {
+	b := newBucket(0, time.Now())
+	if b != nil {
+		t.Fatalf("newBucket(0) = %+v, want nil", b)
+	}
+	b.refill(time.Now())
+	b.take(100)
+	if got := b.waitFor(1e9); got != 0 {
+		t.Errorf("nil bucket waitFor = %v, want 0", got)
+	}
+	if got := b.clamp(1e9); got != 0 {
+		t.Errorf("nil bucket clamp = %v, want 0", got)
+	}
+}
+
+func TestNewUnlimited(t *testing.T) {
+	l := New(0, -1)
+	if l != nil {
+		t.Fatalf("New(0, -1) = %+v, want nil", l)
+	}
+	// nil 的 Limiter 可以安全地调用所有方法。
+	if err := l.Wait(context.Background(), 1000); err != nil {
+		t.Errorf("nil Wait = %v", err)
+	}
+	l.Adjust(1000)
+}
+
+// TestWait 检查配额用完之后 Wait 会阻塞直到 ctx 结束。
+func TestWait(t *testing.T) {
+	tests := []struct {
+		name     string
+		rpm, tpm int
+		tokens   []int // 依次调用 Wait 的预计 token 数，最后一次应当被阻塞
+	}{
+		{"request limit", 2, 0, []int{1, 1, 1}},
+		{"token limit", 0, 100, []int{60, 40, 1}},
+		{"oversized request is clamped to capacity", 0, 100, []int{1000, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.rpm, tt.tpm)
+			last := len(tt.tokens) - 1
+			for _, n := range tt.tokens[:last] {
+				if err := l.Wait(context.Background(), n); err != nil {
+					t.Fatalf("Wait(%d) = %v", n, err)
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if err := l.Wait(ctx, tt.tokens[last]); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Wait after exhausting quota = %v, want context.DeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+// TestAdjust 检查实际用量少于预计时返还配额，多于预计时继续扣除。
+func TestAdjust(t *testing.T) {
+	l := New(0, 100)
+	if err := l.Wait(context.Background(), 100); err != nil {
+		t.Fatal(err)
+	}
+	l.Adjust(-50)
+	if err := l.Wait(context.Background(), 50); err != nil {
+		t.Fatalf("Wait after returning quota = %v", err)
+	}
+
+	l.Adjust(1000)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait after overdraft = %v, want context.DeadlineExceeded", err)
+	}
+}
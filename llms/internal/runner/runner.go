@@ -0,0 +1,80 @@
+// Package runner 提供各供应商共用的调用流程：回调、客户端限流和并发受限的批量生成。
+// 供应商只需要实现一次请求的发送和响应转换，其余部分由 Runner 统一处理。
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zideajang/langChaingo/callbacks"
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/internal/batch"
+	"github.com/zideajang/langChaingo/llms/internal/ratelimit"
+)
+
+// Runner 保存与供应商无关的调用配置，各供应商的 LLM 把它作为字段，由各自的选项设置。
+type Runner struct {
+	// Provider 是批量生成的错误信息中使用的供应商名称。
+	Provider string
+	// MaxConcurrency 是批量生成时的最大并发请求数，小于等于0时不限制。
+	MaxConcurrency int
+	// Limiter 是客户端限流器，为nil时不限流。
+	Limiter *ratelimit.Limiter
+	// Callbacks 接收这个实例上所有调用的事件，为nil时不发送。
+	Callbacks callbacks.Handler
+}
+
+// New 创建一个使用默认并发数的 Runner。
+func New(provider string) Runner {
+	return Runner{Provider: provider, MaxConcurrency: batch.DefaultMaxConcurrency}
+}
+
+// SetRateLimit 开启客户端限流，传入 0 表示不限制该项。
+func (r *Runner) SetRateLimit(requestsPerMinute, tokensPerMinute int) {
+	r.Limiter = ratelimit.New(requestsPerMinute, tokensPerMinute)
+}
+
+// GenerateFunc 向供应商发送一次请求，并把响应转换为 llms.ContentResponse。
+type GenerateFunc func(ctx context.Context, messages []llms.Message, opts *llms.CallOptions) (*llms.ContentResponse, error)
+
+// GenerateContent 向回调发送 LLM 事件，按估算的 token 数等待限流配额后调用 generate，
+// 拿到响应后再按实际用量修正限流器。
+func (r *Runner) GenerateContent(ctx context.Context, messages []llms.Message, opts []llms.CallOption, generate GenerateFunc) (*llms.ContentResponse, error) {
+	return callbacks.RunLLM(ctx, r.Callbacks, messages, opts, func(opts []llms.CallOption) (*llms.ContentResponse, error) {
+		callOpts := llms.NewCallOptions(opts...)
+		estimated := llms.EstimateCallTokens(messages, callOpts)
+		if err := r.Limiter.Wait(ctx, estimated); err != nil {
+			return nil, err
+		}
+		resp, err := generate(ctx, messages, callOpts)
+		if err != nil {
+			return nil, err
+		}
+		if used := resp.Usage.TotalTokens; used > 0 {
+			r.Limiter.Adjust(used - estimated)
+		}
+		return resp, nil
+	})
+}
+
+// GenerateBatch 并发地为每一个提示调用 generateContent，同时进行的请求数受 MaxConcurrency 限制，
+// 并按输入顺序返回每个提示各自的成功或失败。
+func (r *Runner) GenerateBatch(
+	ctx context.Context,
+	prompts []string,
+	opts []llms.CallOption,
+	generateContent func(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error),
+) []llms.BatchResult {
+	results := make([]llms.BatchResult, len(prompts))
+	batch.Run(ctx, len(prompts), r.MaxConcurrency, func(ctx context.Context, i int) {
+		results[i] = llms.BatchResult{Index: i, Prompt: prompts[i]}
+		resp, err := generateContent(ctx, []llms.Message{llms.UserMessage(prompts[i])}, opts...)
+		if err != nil {
+			results[i].Err = fmt.Errorf("%s Generate for prompt %d failed: %w", r.Provider, i, err)
+			return
+		}
+		results[i].Completion = resp.Content
+		results[i].Response = resp
+	})
+	return results
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// TestGenerateContentPassesCallOptions 检查调用选项被合并后传给 generate。
+func TestGenerateContentPassesCallOptions(t *testing.T) {
+	r := New("Test")
+	var got *llms.CallOptions
+	_, err := r.GenerateContent(context.Background(), []llms.Message{llms.UserMessage("hi")},
+		[]llms.CallOption{llms.WithModel("m1")},
+		func(ctx context.Context, messages []llms.Message, opts *llms.CallOptions) (*llms.ContentResponse, error) {
+			got = opts
+			return &llms.ContentResponse{Content: "ok"}, nil
+		})
+	if err != nil {
+		t.Fatalf("GenerateContent: %v", err)
+	}
+	if got == nil || got.Model != "m1" {
+		t.Fatalf("call options = %+v, want Model m1", got)
+	}
+}
+
+// TestGenerateContentAdjustsLimiter 检查实际用量超过估算时，下一次调用需要等待补充的配额。
+func TestGenerateContentAdjustsLimiter(t *testing.T) {
+	r := New("Test")
+	r.SetRateLimit(0, 60)
+	generate := func(ctx context.Context, messages []llms.Message, opts *llms.CallOptions) (*llms.ContentResponse, error) {
+		return &llms.ContentResponse{Usage: llms.Usage{TotalTokens: 600}}, nil
+	}
+	messages := []llms.Message{llms.UserMessage("hi")}
+	if _, err := r.GenerateContent(context.Background(), messages, nil, generate); err != nil {
+		t.Fatalf("first GenerateContent: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := r.GenerateContent(ctx, messages, nil, generate)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second GenerateContent error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// TestGenerateBatch 检查批量结果按输入顺序返回，失败的提示带有供应商名称和序号。
+func TestGenerateBatch(t *testing.T) {
+	r := New("Test")
+	r.MaxConcurrency = 2
+	errBoom := errors.New("boom")
+	generateContent := func(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error) {
+		prompt := messages[0].Content
+		if prompt == "bad" {
+			return nil, errBoom
+		}
+		return &llms.ContentResponse{Content: strings.ToUpper(prompt)}, nil
+	}
+
+	prompts := []string{"a", "bad", "c", "d"}
+	results := r.GenerateBatch(context.Background(), prompts, nil, generateContent)
+	if len(results) != len(prompts) {
+		t.Fatalf("got %d results, want %d", len(results), len(prompts))
+	}
+	for i, res := range results {
+		if res.Index != i || res.Prompt != prompts[i] {
+			t.Errorf("results[%d] = {Index: %d, Prompt: %q}, want {%d, %q}", i, res.Index, res.Prompt, i, prompts[i])
+		}
+		if prompts[i] == "bad" {
+			want := fmt.Sprintf("Test Generate for prompt %d failed", i)
+			if !errors.Is(res.Err, errBoom) || !strings.Contains(res.Err.Error(), want) {
+				t.Errorf("results[%d].Err = %v, want %q wrapping errBoom", i, res.Err, want)
+			}
+			continue
+		}
+		if res.Err != nil || res.Completion != strings.ToUpper(prompts[i]) {
+			t.Errorf("results[%d] = {Completion: %q, Err: %v}", i, res.Completion, res.Err)
+		}
+	}
+}
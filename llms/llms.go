@@ -1,6 +1,80 @@
 package llms
 
+import (
+	"context"
+	"time"
+)
+
+// LLM 是所有大语言模型供应商都需要实现的最基础接口。
+// opts 用于为单次调用设置温度、最大 token 数等生成参数。
 type LLM interface {
-	Call(prompt string) (string, error)
-	Generate(prompts []string) ([]string, error)
+	Call(prompt string, opts ...CallOption) (string, error)
+	Generate(prompts []string, opts ...CallOption) ([]string, error)
+}
+
+// ContextLLM 在 LLM 的基础上提供以 context 为第一个参数的调用方式。
+// 调用方可以通过 ctx 取消正在进行的请求，或者为请求设置截止时间。
+type ContextLLM interface {
+	LLM
+	CallContext(ctx context.Context, prompt string, opts ...CallOption) (string, error)
+	GenerateContext(ctx context.Context, prompts []string, opts ...CallOption) ([]string, error)
+}
+
+// ChatModel 表示支持多轮对话的模型，可以一次发送包含系统提示、
+// 历史回复在内的完整消息列表。
+type ChatModel interface {
+	GenerateContent(ctx context.Context, messages []Message, opts ...CallOption) (*ContentResponse, error)
+}
+
+// 常见的结束原因，各供应商返回的值会被统一为下面几种。
+const (
+	// FinishReasonStop 表示模型自然结束或遇到了停止序列。
+	FinishReasonStop = "stop"
+	// FinishReasonLength 表示输出达到了最大 token 数被截断。
+	FinishReasonLength = "length"
+	// FinishReasonToolCalls 表示模型请求调用工具。
+	FinishReasonToolCalls = "tool_calls"
+)
+
+// ContentResponse 是 GenerateContent 返回的与供应商无关的响应。
+type ContentResponse struct {
+	Content      string // 模型生成的内容
+	Model        string // 实际响应请求的模型名称
+	ID           string // 供应商返回的响应 ID，没有时为空
+	FinishReason string // 结束原因，例如 FinishReasonStop、FinishReasonLength
+	Usage        Usage
+	Timings      Timings
+
+	// ToolCalls 是模型请求调用的工具，没有时为空。
+	ToolCalls []ToolCall
+
+	// GenerationInfo 保存供应商返回的原始元数据，键名与供应商的字段名保持一致。
+	GenerationInfo map[string]any
+}
+
+// Usage 表示一次调用的 token 使用情况。
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`     // 提示部分消耗的 token 数
+	CompletionTokens int `json:"completion_tokens"` // 生成部分消耗的 token 数
+	TotalTokens      int `json:"total_tokens"`      // 总 token 数
+}
+
+// Timings 表示一次调用的耗时情况。
+// Latency 由客户端测量，其余字段由供应商上报，不支持的供应商为零值。
+type Timings struct {
+	Latency    time.Duration // 从发出请求到拿到完整响应的时间
+	Total      time.Duration // 服务端处理请求的总时间
+	Load       time.Duration // 加载模型的时间
+	PromptEval time.Duration // 处理提示的时间
+	Eval       time.Duration // 生成内容的时间
+}
+
+// GenerateFromSinglePrompt 把单个提示包装成一条用户消息后交给 ChatModel，
+// 并只返回生成的文本内容。
+func GenerateFromSinglePrompt(ctx context.Context, model ChatModel, prompt string, opts ...CallOption) (string, error) {
+	resp, err := model.GenerateContent(ctx, []Message{UserMessage(prompt)}, opts...)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
 }
@@ -0,0 +1,48 @@
+package llms
+
+// Role 表示聊天消息发送者的角色。
+type Role string
+
+const (
+	// RoleSystem 是系统提示使用的角色。
+	RoleSystem Role = "system"
+	// RoleUser 是用户输入使用的角色。
+	RoleUser Role = "user"
+	// RoleAssistant 是模型回复使用的角色。
+	RoleAssistant Role = "assistant"
+	// RoleTool 是工具执行结果使用的角色。
+	RoleTool Role = "tool"
+)
+
+// Message 是与供应商无关的聊天消息，会被映射为各个客户端自己的消息结构。
+type Message struct {
+	Role    Role   `json:"role"`    // 消息发送者的角色
+	Content string `json:"content"` // 消息的文本内容
+
+	// ToolCalls 是模型在这条回复中请求调用的工具，只出现在 RoleAssistant 消息中。
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID 是工具执行结果对应的调用 ID，只出现在 RoleTool 消息中。
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// Name 是工具执行结果对应的工具名称，只出现在 RoleTool 消息中。
+	Name string `json:"name,omitempty"`
+}
+
+// SystemMessage 创建一条系统消息。
+func SystemMessage(content string) Message {
+	return Message{Role: RoleSystem, Content: content}
+}
+
+// UserMessage 创建一条用户消息。
+func UserMessage(content string) Message {
+	return Message{Role: RoleUser, Content: content}
+}
+
+// AssistantMessage 创建一条模型回复消息。
+func AssistantMessage(content string) Message {
+	return Message{Role: RoleAssistant, Content: content}
+}
+
+// ToolMessage 创建一条工具执行结果消息，toolCallID 和 name 来自模型返回的 ToolCall。
+func ToolMessage(toolCallID, name, content string) Message {
+	return Message{Role: RoleTool, Content: content, ToolCallID: toolCallID, Name: name}
+}
@@ -1,12 +1,20 @@
 package ollamaclient
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms/internal/httpretry"
 )
 
 // --- Constants ---
@@ -17,6 +25,10 @@ const (
 	chatAPIPath = "/api/chat"
 	// DefaultBaseURL 是Ollama服务的默认基础URL。
 	DefaultBaseURL = "http://localhost:11434"
+	// defaultPort 是Ollama服务的默认端口。
+	defaultPort = "11434"
+	// hostEnvVar 是Ollama官方客户端用于指定服务地址的环境变量。
+	hostEnvVar = "OLLAMA_HOST"
 )
 
 // --- Errors ---
@@ -28,29 +40,168 @@ var ErrEmptyResponse = errors.New("empty response")
 // Client 表示与Ollama API交互的客户端。
 type Client struct {
 	// apikey 存储API密钥，对于Ollama通常为空或不适用。
+	// 通过反向代理访问Ollama时，会以Bearer token的形式发送。
 	apikey string
 	// baseURL 存储Ollama服务的基准URL。
 	baseURL string
+	// httpClient 用于发送HTTP请求，默认为http.DefaultClient。
+	httpClient *http.Client
+	// headers 是附加在每个请求上的自定义请求头。
+	headers http.Header
+	// timeout 是单次请求的超时时间，为0时不额外设置超时。
+	timeout time.Duration
+	// retry 是请求失败时的重试策略。
+	retry httpretry.Policy
+}
+
+// Option 是用于配置Client的函数选项。
+type Option func(*Client)
+
+// WithBaseURL 指定Ollama服务的基础URL，优先于 OLLAMA_HOST 环境变量。
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader 为每个请求附加一个自定义请求头。
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Add(key, value)
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间，流式请求的超时覆盖读取整个流的过程。
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1表示不重试。
+func WithMaxAttempts(attempts int) Option {
+	return func(c *Client) {
+		c.retry.MaxAttempts = attempts
+	}
+}
+
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+// 实际等待时间在指数增长的上限内随机取值；服务端返回 Retry-After 时以它为准（不超过 max）。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(c *Client) {
+		c.retry.InitialBackoff = initial
+		c.retry.MaxBackoff = max
+	}
 }
 
 // --- Client Constructor ---
 
 // New 创建并返回一个新的Ollama Client实例。
 // apikey 参数目前对Ollama服务通常不使用，但保留以备将来兼容性。
-func New(apikey string) (*Client, error) {
+// 没有通过 WithBaseURL 指定地址时，会读取 OLLAMA_HOST 环境变量，都没有时使用 DefaultBaseURL。
+func New(apikey string, opts ...Option) (*Client, error) {
 	c := &Client{
-		apikey:  apikey,
-		baseURL: DefaultBaseURL, // 使用常量设置默认URL
+		apikey:     apikey,
+		httpClient: http.DefaultClient,
+		retry:      httpretry.DefaultPolicy(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.baseURL == "" {
+		baseURL, err := baseURLFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		c.baseURL = baseURL
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
 	}
 	return c, nil
 }
 
+// baseURLFromEnv 根据 OLLAMA_HOST 环境变量计算基础URL。
+// 与Ollama官方客户端一致，支持 "host"、"host:port"、"http(s)://host:port/path" 等写法，
+// 缺省协议为http，缺省端口为11434。
+func baseURLFromEnv() (string, error) {
+	host := strings.TrimSpace(os.Getenv(hostEnvVar))
+	if host == "" {
+		return DefaultBaseURL, nil
+	}
+
+	scheme, port := "http", defaultPort
+	if i := strings.Index(host, "://"); i >= 0 {
+		scheme, host = host[:i], host[i+3:]
+		switch scheme {
+		case "http":
+		case "https":
+			port = "443"
+		default:
+			return "", fmt.Errorf("unsupported scheme %q in %s", scheme, hostEnvVar)
+		}
+	}
+
+	path := ""
+	if i := strings.Index(host, "/"); i >= 0 {
+		host, path = host[:i], strings.TrimRight(host[i:], "/")
+	}
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, port = h, p
+	} else {
+		// 没有端口的IPv6地址，例如 [::1]，JoinHostPort 会重新加上方括号。
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port) + path, nil
+}
+
 // --- Request and Response Payloads ---
 
 // Message 结构体表示聊天中的一条消息。
 type Message struct {
-	Role    string `json:"role"`    // 消息发送者的角色 (e.g., "user", "system", "assistant")
+	Role    string `json:"role"`    // 消息发送者的角色 (e.g., "user", "system", "assistant", "tool")
 	Content string `json:"content"` // 消息的文本内容
+
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"` // assistant消息中模型请求的工具调用
+	ToolName  string     `json:"tool_name,omitempty"`  // tool消息对应的工具名称
+}
+
+// Tool 结构体表示请求中声明的一个工具，格式与OpenAI兼容。
+type Tool struct {
+	Type     string             `json:"type"` // 目前只支持 "function"
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition 结构体描述一个可以被模型调用的函数。
+type FunctionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"` // 参数的JSON Schema
+}
+
+// ToolCall 结构体表示模型请求的一次工具调用。Ollama不返回调用ID。
+type ToolCall struct {
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall 结构体表示一次函数调用。
+// 与OpenAI不同，Ollama的参数是JSON对象而不是JSON字符串。
+type FunctionCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
 }
 
 // ChatRequest 结构体定义了发送到Ollama API的聊天请求体。
@@ -58,27 +209,65 @@ type ChatRequest struct {
 	Model    string    `json:"model"`    // 要使用的Ollama模型名称
 	Messages []Message `json:"messages"` // 聊天消息列表
 	Stream   bool      `json:"stream"`   // 是否以流式方式获取响应 (false表示获取完整响应)
+
+	// Options 是采样参数，为nil时使用模型默认值。
+	Options *Options `json:"options,omitempty"`
+
+	// Tools 是模型可以调用的工具。Ollama不支持tool_choice。
+	Tools []Tool `json:"tools,omitempty"`
+
+	// Format 为 "json" 时开启JSON模式，也可以是一个JSON Schema对象，要求输出满足该schema。
+	Format any `json:"format,omitempty"`
+
+	// StreamingFunc 不为空时以流式方式请求，每收到一段增量内容就回调一次。
+	// 回调返回错误会中止读取流。
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
+}
+
+// Options 结构体对应Ollama请求中的options对象，用于设置采样参数。
+// 所有字段为nil时不会出现在请求体中。
+type Options struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	TopK             *int     `json:"top_k,omitempty"`
+	NumPredict       *int     `json:"num_predict,omitempty"` // 最多生成的token数
+	Stop             []string `json:"stop,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
 }
 
 // ollamaChatResponsePayload 结构体用于解析Ollama API返回的完整JSON响应。
 // 它包含了模型生成的消息以及各种性能指标。
 type ollamaChatResponsePayload struct {
-	Model         string  `json:"model"`
-	CreatedAt     string  `json:"created_at"`
-	Message       Message `json:"message"` // LLM 生成的回复消息
-	Done          bool    `json:"done"`
-	TotalDuration int64   `json:"total_duration"`
-	LoadDuration  int64   `json:"load_duration"`
-	PromptEvalCount int `json:"prompt_eval_count"`
-	PromptEvalDuration int64 `json:"prompt_eval_duration"`
-	EvalCount     int     `json:"eval_count"`
-	EvalDuration  int64   `json:"eval_duration"`
-}
-
-// ChatResponse 结构体是Ollama客户端向外部暴露的简化聊天响应。
-// 它只包含最重要的信息：LLM生成的内容。
+	Model              string  `json:"model"`
+	CreatedAt          string  `json:"created_at"`
+	Message            Message `json:"message"` // LLM 生成的回复消息
+	Done               bool    `json:"done"`
+	DoneReason         string  `json:"done_reason"`
+	TotalDuration      int64   `json:"total_duration"`
+	LoadDuration       int64   `json:"load_duration"`
+	PromptEvalCount    int     `json:"prompt_eval_count"`
+	PromptEvalDuration int64   `json:"prompt_eval_duration"`
+	EvalCount          int     `json:"eval_count"`
+	EvalDuration       int64   `json:"eval_duration"`
+	Error              string  `json:"error,omitempty"` // 流式响应中途出错时返回的错误信息
+}
+
+// ChatResponse 结构体是Ollama客户端向外部暴露的聊天响应。
+// 除了LLM生成的内容，还包含token计数和各阶段耗时(纳秒)。
 type ChatResponse struct {
-	Content string // LLM生成的内容
+	Content            string // LLM生成的内容
+	Model              string
+	CreatedAt          string
+	DoneReason         string // 结束原因，例如 "stop"、"length"
+	TotalDuration      int64
+	LoadDuration       int64
+	PromptEvalCount    int // 提示部分的token数
+	PromptEvalDuration int64
+	EvalCount          int // 生成部分的token数
+	EvalDuration       int64
+	ToolCalls          []ToolCall // 模型请求的工具调用
 }
 
 // --- Internal HTTP Request Method ---
@@ -92,43 +281,21 @@ func (c *Client) doChat(ctx context.Context, payload *ChatRequest) (*ollamaChatR
 		payload.Model = DefaultChatModel
 	}
 
-	// 客户端期望Ollama API返回一个完整的、一次性的响应，而不是流式传输。
-	payload.Stream = false
-
-	// 将请求体转换为JSON字节数组。
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal JSON payload: %w", err)
-	}
-
-	// 将字节数组包装成io.Reader，以便http.NewRequestWithContext使用。
-	body := bytes.NewReader(payloadBytes)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
 
-	// 构建完整的请求URL。
-	url := c.baseURL + chatAPIPath
-	
-	// 创建新的HTTP POST请求。
-	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-	
-	// 设置请求头，指定内容类型为JSON。
-	req.Header.Set("Content-Type", "application/json")
+	// 只有设置了 StreamingFunc 时才使用流式传输，否则期望一次性返回完整响应。
+	payload.Stream = payload.StreamingFunc != nil
 
-	// 发送HTTP请求。
-	r, err := http.DefaultClient.Do(req)
+	r, err := c.doRequest(ctx, http.MethodPost, chatAPIPath, payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
+		return nil, err
 	}
 	defer r.Body.Close() // 确保响应体在使用后关闭
 
-	// 检查HTTP响应状态码。
-	if r.StatusCode != http.StatusOK {
-		var errRes map[string]interface{}
-		// 尝试解析错误响应体。
-		json.NewDecoder(r.Body).Decode(&errRes)
-		return nil, fmt.Errorf("ollama API request failed with status %d: %v", r.StatusCode, errRes)
+	// 流式响应需要逐行解析 NDJSON。
+	if payload.Stream {
+		return parseStream(ctx, r.Body, payload.StreamingFunc)
 	}
 
 	// 声明一个变量来存储解析后的Ollama API响应。
@@ -141,6 +308,115 @@ func (c *Client) doChat(ctx context.Context, payload *ChatRequest) (*ollamaChatR
 	return &response, nil
 }
 
+// parseStream 解析Ollama /api/chat 返回的NDJSON流。
+// 每一行都是一个ollamaChatResponsePayload，其中message.content是增量内容，
+// done为true的最后一行携带各项性能指标。返回值是聚合后的完整响应。
+func parseStream(ctx context.Context, body io.Reader, fn func(ctx context.Context, chunk []byte) error) (*ollamaChatResponsePayload, error) {
+	var (
+		response  ollamaChatResponsePayload
+		content   strings.Builder
+		toolCalls []ToolCall
+	)
+	scanner := bufio.NewScanner(body)
+	// 单行可能较长，放宽默认的64KB限制。
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk ollamaChatResponsePayload
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode ollama stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, &APIError{Provider: providerName, StatusCode: http.StatusOK, Message: chunk.Error}
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if err := fn(ctx, []byte(chunk.Message.Content)); err != nil {
+				return nil, fmt.Errorf("streaming func returned an error: %w", err)
+			}
+		}
+		// 工具调用会完整地出现在某一个数据块中，直接收集即可。
+		toolCalls = append(toolCalls, chunk.Message.ToolCalls...)
+		if chunk.Done {
+			response = chunk
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ollama stream: %w", err)
+	}
+	if !response.Done {
+		return nil, fmt.Errorf("ollama stream ended before done message")
+	}
+	response.Message.Role = "assistant"
+	response.Message.Content = content.String()
+	response.Message.ToolCalls = toolCalls
+	return &response, nil
+}
+
+// withTimeout 在设置了超时时间时，为本次请求派生一个带超时的ctx。
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return ctx, func() {}
+}
+
+// doRequest 向Ollama API发送一个HTTP请求，payload不为nil时以JSON作为请求体。
+// 遇到限流、服务端临时错误或网络错误时按照重试策略重试；
+// 非200响应统一转换为 *APIError，成功时由调用方负责关闭响应体。
+func (c *Client) doRequest(ctx context.Context, method, path string, payload any) (*http.Response, error) {
+	// 将请求体转换为JSON字节数组。
+	var payloadBytes []byte
+	if payload != nil {
+		var err error
+		payloadBytes, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON payload: %w", err)
+		}
+	}
+
+	// 构建完整的请求URL。
+	url := c.baseURL + path
+
+	// 每次尝试都重新创建请求，以便重新读取请求体。
+	r, err := httpretry.Do(ctx, c.httpClient, c.retry, func(ctx context.Context) (*http.Request, error) {
+		var body io.Reader
+		if payloadBytes != nil {
+			body = bytes.NewReader(payloadBytes)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
+
+		// 设置请求头，指定内容类型为JSON，并附加自定义请求头。
+		if payloadBytes != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.apikey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apikey)
+		}
+		for key, values := range c.headers {
+			req.Header[key] = values
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+
+	// 检查HTTP响应状态码。
+	if r.StatusCode != http.StatusOK {
+		defer r.Body.Close()
+		return nil, newAPIError(r)
+	}
+	return r, nil
+}
+
 // --- Public Chat Method ---
 
 // Chat 方法是Ollama客户端的公共入口点，用于发送聊天请求。
@@ -150,12 +426,21 @@ func (c *Client) Chat(ctx context.Context, r *ChatRequest) (*ChatResponse, error
 	if err != nil {
 		return nil, err
 	}
-	// 检查Ollama的响应消息内容是否为空。
-	if resp.Message.Content == "" {
+	// 检查Ollama的响应消息内容和工具调用是否都为空。
+	if resp.Message.Content == "" && len(resp.Message.ToolCalls) == 0 {
 		return nil, ErrEmptyResponse
 	}
-	// 返回一个简化的ChatResponse。
 	return &ChatResponse{
-		Content: resp.Message.Content,
+		Content:            resp.Message.Content,
+		ToolCalls:          resp.Message.ToolCalls,
+		Model:              resp.Model,
+		CreatedAt:          resp.CreatedAt,
+		DoneReason:         resp.DoneReason,
+		TotalDuration:      resp.TotalDuration,
+		LoadDuration:       resp.LoadDuration,
+		PromptEvalCount:    resp.PromptEvalCount,
+		PromptEvalDuration: resp.PromptEvalDuration,
+		EvalCount:          resp.EvalCount,
+		EvalDuration:       resp.EvalDuration,
 	}, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,146 @@
+package ollamaclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestBaseURLFromEnv 检查 OLLAMA_HOST 的各种写法。
+func TestBaseURLFromEnv(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{"", DefaultBaseURL, false},
+		{"   ", DefaultBaseURL, false},
+		{"gpu-box", "http://gpu-box:11434", false},
+		{"gpu-box:8080", "http://gpu-box:8080", false},
+		{"0.0.0.0", "http://0.0.0.0:11434", false},
+		{":8080", "http://127.0.0.1:8080", false},
+		{"http://gpu-box", "http://gpu-box:11434", false},
+		{"https://ollama.example.com", "https://ollama.example.com:443", false},
+		{"https://ollama.example.com:8443/api/proxy/", "https://ollama.example.com:8443/api/proxy", false},
+		{"gpu-box/ollama", "http://gpu-box:11434/ollama", false},
+		{"[::1]", "http://[::1]:11434", false},
+		{"[::1]:9000", "http://[::1]:9000", false},
+		{"ftp://gpu-box", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			t.Setenv(hostEnvVar, tt.host)
+			got, err := baseURLFromEnv()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("baseURLFromEnv() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("baseURLFromEnv() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewBaseURLPrecedence 检查 WithBaseURL 优先于 OLLAMA_HOST。
+func TestNewBaseURLPrecedence(t *testing.T) {
+	t.Setenv(hostEnvVar, "gpu-box")
+	c, err := New("", WithBaseURL("http://localhost:9999/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.baseURL != "http://localhost:9999" {
+		t.Errorf("baseURL = %q, want http://localhost:9999", c.baseURL)
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantContent string
+		wantTools   int
+		wantErr     string
+	}{
+		{
+			name: "content and done",
+			body: `{"message":{"role":"assistant","content":"Hel"},"done":false}
+{"message":{"role":"assistant","content":"lo"},"done":false}
+
+{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","eval_count":2}
+`,
+			wantContent: "Hello",
+		},
+		{
+			name: "tool calls",
+			body: `{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"Paris"}}}]},"done":false}
+{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop"}
+`,
+			wantTools: 1,
+		},
+		{
+			name:    "truncated before done",
+			body:    `{"message":{"role":"assistant","content":"Hel"},"done":false}` + "\n",
+			wantErr: "ended before done message",
+		},
+		{
+			name:    "empty body",
+			body:    "",
+			wantErr: "ended before done message",
+		},
+		{
+			name:    "error line",
+			body:    `{"error":"model not found"}` + "\n",
+			wantErr: "model not found",
+		},
+		{
+			name:    "invalid json",
+			body:    "{not json}\n",
+			wantErr: "failed to decode ollama stream chunk",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var streamed strings.Builder
+			resp, err := parseStream(context.Background(), strings.NewReader(tt.body), func(ctx context.Context, chunk []byte) error {
+				streamed.Write(chunk)
+				return nil
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseStream error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStream: %v", err)
+			}
+			if resp.Message.Content != tt.wantContent || streamed.String() != tt.wantContent {
+				t.Errorf("content = %q, streamed %q, want %q", resp.Message.Content, streamed.String(), tt.wantContent)
+			}
+			if len(resp.Message.ToolCalls) != tt.wantTools {
+				t.Errorf("tool calls = %+v, want %d", resp.Message.ToolCalls, tt.wantTools)
+			}
+			if !resp.Done || resp.Message.Role != "assistant" {
+				t.Errorf("response = %+v, want done assistant message", resp)
+			}
+		})
+	}
+}
+
+// TestParseStreamCallbackError 检查流式回调返回的错误会中止读取。
+func TestParseStreamCallbackError(t *testing.T) {
+	errStop := errors.New("stop")
+	body := `{"message":{"content":"a"},"done":false}
+{"message":{"content":"b"},"done":true}
+`
+	_, err := parseStream(context.Background(), strings.NewReader(body), func(context.Context, []byte) error {
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("parseStream error = %v, want errStop", err)
+	}
+}
@@ -0,0 +1,53 @@
+package ollamaclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// embedAPIPath 是Ollama API中用于生成向量的路由。
+const embedAPIPath = "/api/embed"
+
+// EmbedRequest 结构体定义了发送到 /api/embed 的请求体，一次可以为多段文本生成向量。
+type EmbedRequest struct {
+	Model    string   `json:"model"`              // 用于生成向量的模型名称
+	Input    []string `json:"input"`              // 需要生成向量的文本
+	Truncate *bool    `json:"truncate,omitempty"` // 文本超出上下文长度时是否截断，默认为true
+	Options  *Options `json:"options,omitempty"`
+}
+
+// EmbedResponse 结构体是 /api/embed 返回的响应，Embeddings 与 Input 一一对应。
+type EmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float32 `json:"embeddings"`
+	TotalDuration   int64       `json:"total_duration"`
+	LoadDuration    int64       `json:"load_duration"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+}
+
+// Embed 为请求中的每一段文本生成向量，与聊天请求共用基础URL、HTTP客户端、请求头和重试策略。
+func (c *Client) Embed(ctx context.Context, payload *EmbedRequest) (*EmbedResponse, error) {
+	if payload.Model == "" {
+		payload.Model = DefaultChatModel
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	r, err := c.doRequest(ctx, http.MethodPost, embedAPIPath, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	var response EmbedResponse
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode ollama embed response: %w", err)
+	}
+	if len(response.Embeddings) != len(payload.Input) {
+		return nil, fmt.Errorf("%w: got %d embeddings for %d inputs", ErrEmptyResponse, len(response.Embeddings), len(payload.Input))
+	}
+	return &response, nil
+}
@@ -0,0 +1,42 @@
+package ollamaclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/internal/httpretry"
+	"github.com/zideajang/langChaingo/llms/internal/httputil"
+)
+
+// providerName 是APIError中使用的供应商名称。
+const providerName = "ollama"
+
+// APIError 是Ollama接口返回非200响应时的错误类型，与 llms.APIError 相同，
+// 可以通过 errors.As 取出，或者通过 errors.Is 与 llms 包中的哨兵错误比较。
+type APIError = llms.APIError
+
+// ollamaErrorPayload 是Ollama错误响应体的结构，例如 {"error":"model 'x' not found"}。
+type ollamaErrorPayload struct {
+	Error string `json:"error"`
+}
+
+// newAPIError 读取错误响应并构造 APIError。
+func newAPIError(r *http.Response) *APIError {
+	body := httputil.ReadErrorBody(r.Body)
+	apiErr := &APIError{
+		Provider:   providerName,
+		StatusCode: r.StatusCode,
+		RequestID:  httputil.RequestID(r.Header),
+		Retryable:  httpretry.IsRetryableStatus(r.StatusCode),
+	}
+
+	var payload ollamaErrorPayload
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error != "" {
+		apiErr.Message = payload.Error
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
@@ -0,0 +1,218 @@
+package ollamaclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 模型管理相关的路由。
+const (
+	tagsAPIPath    = "/api/tags"
+	showAPIPath    = "/api/show"
+	pullAPIPath    = "/api/pull"
+	deleteAPIPath  = "/api/delete"
+	copyAPIPath    = "/api/copy"
+	psAPIPath      = "/api/ps"
+	versionAPIPath = "/api/version"
+)
+
+// ModelDetails 结构体描述模型的格式、家族、参数量和量化方式。
+type ModelDetails struct {
+	ParentModel       string   `json:"parent_model"`
+	Format            string   `json:"format"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families"`
+	ParameterSize     string   `json:"parameter_size"`
+	QuantizationLevel string   `json:"quantization_level"`
+}
+
+// Model 结构体是 /api/tags 返回的一个本地模型。
+type Model struct {
+	Name       string       `json:"name"`
+	Model      string       `json:"model"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Size       int64        `json:"size"` // 模型文件的字节数
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+// RunningModel 结构体是 /api/ps 返回的一个已加载到内存中的模型。
+type RunningModel struct {
+	Name          string       `json:"name"`
+	Model         string       `json:"model"`
+	Size          int64        `json:"size"`      // 占用的内存字节数
+	SizeVRAM      int64        `json:"size_vram"` // 其中占用显存的字节数
+	Digest        string       `json:"digest"`
+	Details       ModelDetails `json:"details"`
+	ExpiresAt     time.Time    `json:"expires_at"` // 空闲后被卸载的时间
+	ContextLength int          `json:"context_length"`
+}
+
+// ShowRequest 结构体定义了发送到 /api/show 的请求体。
+type ShowRequest struct {
+	Model   string `json:"model"`
+	Verbose bool   `json:"verbose,omitempty"` // 为true时 ModelInfo 中包含完整的分词器数据
+}
+
+// ShowResponse 结构体是 /api/show 返回的模型信息。
+type ShowResponse struct {
+	Modelfile    string         `json:"modelfile"`
+	Parameters   string         `json:"parameters"` // Modelfile 中的 PARAMETER，每行一个
+	Template     string         `json:"template"`   // 提示词模板
+	System       string         `json:"system"`
+	License      string         `json:"license"`
+	Details      ModelDetails   `json:"details"`
+	ModelInfo    map[string]any `json:"model_info"`   // 键名形如 "llama.context_length"
+	Capabilities []string       `json:"capabilities"` // 例如 "completion"、"tools"、"embedding"
+	ModifiedAt   time.Time      `json:"modified_at"`
+}
+
+// ContextLength 返回模型支持的最大上下文长度，模型信息中没有时返回0。
+// Ollama 的 model_info 以 "<架构>.context_length" 作为键名，例如 "qwen3.context_length"。
+func (r *ShowResponse) ContextLength() int {
+	arch, _ := r.ModelInfo["general.architecture"].(string)
+	if n, ok := r.ModelInfo[arch+".context_length"].(float64); ok {
+		return int(n)
+	}
+	return 0
+}
+
+// PullRequest 结构体定义了发送到 /api/pull 的请求体。
+type PullRequest struct {
+	Model    string `json:"model"`
+	Insecure bool   `json:"insecure,omitempty"` // 允许连接不安全的镜像仓库
+}
+
+// PullProgress 结构体是拉取模型过程中的一条进度，下载某一层时 Total 和 Completed 为字节数，
+// 其余阶段只有 Status，例如 "pulling manifest"、"verifying sha256 digest"、"success"。
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+}
+
+// ListModels 列出本地已经下载的模型。
+func (c *Client) ListModels(ctx context.Context) ([]Model, error) {
+	var response struct {
+		Models []Model `json:"models"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, tagsAPIPath, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Models, nil
+}
+
+// ListRunningModels 列出当前加载在内存中的模型。
+func (c *Client) ListRunningModels(ctx context.Context) ([]RunningModel, error) {
+	var response struct {
+		Models []RunningModel `json:"models"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, psAPIPath, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Models, nil
+}
+
+// ShowModel 返回模型的详细信息，包括模板、参数和上下文长度。
+// 模型不存在时返回的 *APIError 与 llms.ErrModelNotFound 匹配。
+func (c *Client) ShowModel(ctx context.Context, payload *ShowRequest) (*ShowResponse, error) {
+	var response ShowResponse
+	if err := c.doJSON(ctx, http.MethodPost, showAPIPath, payload, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// DeleteModel 删除本地模型。
+func (c *Client) DeleteModel(ctx context.Context, model string) error {
+	return c.doJSON(ctx, http.MethodDelete, deleteAPIPath, map[string]string{"model": model}, nil)
+}
+
+// CopyModel 把 source 复制为名为 destination 的新模型。
+func (c *Client) CopyModel(ctx context.Context, source, destination string) error {
+	return c.doJSON(ctx, http.MethodPost, copyAPIPath, map[string]string{
+		"source":      source,
+		"destination": destination,
+	}, nil)
+}
+
+// Version 返回Ollama服务的版本号。
+func (c *Client) Version(ctx context.Context) (string, error) {
+	var response struct {
+		Version string `json:"version"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, versionAPIPath, nil, &response); err != nil {
+		return "", err
+	}
+	return response.Version, nil
+}
+
+// PullModel 从镜像仓库拉取模型，fn 不为nil时每收到一条进度就回调一次，回调返回错误会中止拉取。
+// 拉取大模型可能需要很长时间，因此不受 WithTimeout 限制，需要时请通过 ctx 控制。
+func (c *Client) PullModel(ctx context.Context, payload *PullRequest, fn func(PullProgress) error) error {
+	r, err := c.doRequest(ctx, http.MethodPost, pullAPIPath, payload)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	// 响应是每行一个JSON对象的流，出错时该行只包含 error 字段。
+	var last string
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var progress struct {
+			PullProgress
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(line), &progress); err != nil {
+			return fmt.Errorf("failed to decode ollama pull progress: %w", err)
+		}
+		if progress.Error != "" {
+			return &APIError{Provider: providerName, StatusCode: r.StatusCode, Message: progress.Error}
+		}
+		last = progress.Status
+		if fn != nil {
+			if err := fn(progress.PullProgress); err != nil {
+				return fmt.Errorf("pull progress func returned an error: %w", err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read ollama pull progress: %w", err)
+	}
+	if last != "success" {
+		return errors.New("ollama pull ended before success")
+	}
+	return nil
+}
+
+// doJSON 在 WithTimeout 的限制下发送请求，response 不为nil时把响应体解码到其中。
+func (c *Client) doJSON(ctx context.Context, method, path string, payload, response any) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	r, err := c.doRequest(ctx, method, path, payload)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	if response == nil {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(response); err != nil {
+		return fmt.Errorf("failed to decode ollama %s response: %w", path, err)
+	}
+	return nil
+}
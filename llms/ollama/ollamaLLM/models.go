@@ -0,0 +1,95 @@
+package ollamaLLM
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/ollama/internal/ollamaclient"
+)
+
+// 模型管理接口使用的类型与 ollamaclient 中的定义相同。
+type (
+	// Model 是本地已经下载的一个模型。
+	Model = ollamaclient.Model
+	// RunningModel 是当前加载在内存中的一个模型。
+	RunningModel = ollamaclient.RunningModel
+	// ModelDetails 描述模型的格式、家族、参数量和量化方式。
+	ModelDetails = ollamaclient.ModelDetails
+	// ModelInfo 是 ShowModel 返回的模型信息，ContextLength 方法返回模型支持的最大上下文长度。
+	ModelInfo = ollamaclient.ShowResponse
+	// PullProgress 是拉取模型过程中的一条进度。
+	PullProgress = ollamaclient.PullProgress
+)
+
+// ListModels 通过 /api/tags 列出本地已经下载的模型。
+func (l *OllamaLLM) ListModels(ctx context.Context) ([]Model, error) {
+	return l.client.ListModels(ctx)
+}
+
+// ListRunningModels 通过 /api/ps 列出当前加载在内存中的模型。
+func (l *OllamaLLM) ListRunningModels(ctx context.Context) ([]RunningModel, error) {
+	return l.client.ListRunningModels(ctx)
+}
+
+// ShowModel 通过 /api/show 返回模型的模板、参数和上下文长度等信息，model 为空时使用 WithModel 设置的模型。
+// 模型不存在时返回的错误与 llms.ErrModelNotFound 匹配。
+func (l *OllamaLLM) ShowModel(ctx context.Context, model string) (*ModelInfo, error) {
+	if model == "" {
+		model = l.model
+	}
+	return l.client.ShowModel(ctx, &ollamaclient.ShowRequest{Model: model})
+}
+
+// PullModel 通过 /api/pull 拉取模型，progress 不为nil时接收拉取进度。
+// 拉取不受 WithTimeout 限制，需要时请通过 ctx 控制。
+func (l *OllamaLLM) PullModel(ctx context.Context, model string, progress func(PullProgress)) error {
+	var fn func(PullProgress) error
+	if progress != nil {
+		fn = func(p PullProgress) error {
+			progress(p)
+			return nil
+		}
+	}
+	if err := l.client.PullModel(ctx, &ollamaclient.PullRequest{Model: model}, fn); err != nil {
+		return fmt.Errorf("ollama pull %s failed: %w", model, err)
+	}
+	return nil
+}
+
+// DeleteModel 通过 /api/delete 删除本地模型。
+func (l *OllamaLLM) DeleteModel(ctx context.Context, model string) error {
+	return l.client.DeleteModel(ctx, model)
+}
+
+// CopyModel 通过 /api/copy 把 source 复制为名为 destination 的新模型。
+func (l *OllamaLLM) CopyModel(ctx context.Context, source, destination string) error {
+	return l.client.CopyModel(ctx, source, destination)
+}
+
+// Version 通过 /api/version 返回Ollama服务的版本号。
+func (l *OllamaLLM) Version(ctx context.Context) (string, error) {
+	return l.client.Version(ctx)
+}
+
+// EnsureModels 拉取聊天模型和向量模型中本地还没有的模型，进度发送给 WithAutoPull 设置的回调。
+func (l *OllamaLLM) EnsureModels(ctx context.Context) error {
+	models := []string{l.model}
+	if l.embeddingModel != "" && l.embeddingModel != l.model {
+		models = append(models, l.embeddingModel)
+	}
+	for _, model := range models {
+		_, err := l.ShowModel(ctx, model)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, llms.ErrModelNotFound) {
+			return fmt.Errorf("failed to check ollama model %s: %w", model, err)
+		}
+		if err := l.PullModel(ctx, model, l.pullProgress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,9 +2,16 @@ package ollamaLLM
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"sync"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/zideajang/langChaingo/callbacks"
+	"github.com/zideajang/langChaingo/embeddings"
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/internal/runner"
 	"github.com/zideajang/langChaingo/llms/ollama/internal/ollamaclient"
 )
 
@@ -12,15 +19,42 @@ type OllamaLLM struct {
 	// 包含一个客户端和模型名称
 	client *ollamaclient.Client
 	model  string
+	// embeddingModel 是生成向量使用的模型，为空时使用 model
+	embeddingModel string
+
+	// clientOptions 在创建内部客户端时传给 ollamaclient.New
+	clientOptions []ollamaclient.Option
+
+	// runner 负责回调、客户端限流和批量生成
+	runner runner.Runner
+
+	// autoPull 为true时 NewContext 会拉取本地还没有的模型，pullProgress 接收拉取进度，可以为nil
+	autoPull     bool
+	pullProgress func(PullProgress)
 }
 
+var (
+	_ llms.ContextLLM = (*OllamaLLM)(nil)
+	_ llms.ChatModel  = (*OllamaLLM)(nil)
+	_ llms.BatchLLM   = (*OllamaLLM)(nil)
+
+	_ embeddings.EmbedderClient = (*OllamaLLM)(nil)
+)
+
 // Option 的切片
-// New 函数用于创建返回一个 llm 的结构体指针
+// New 函数用于创建返回一个 llm 的结构体指针，它是 NewContext 的简单封装，使用 context.Background()。
 func New(opts ...Option) (*OllamaLLM, error) {
+	return NewContext(context.Background(), opts...)
+}
+
+// NewContext 与 New 相同，设置了 WithAutoPull 时使用 ctx 控制模型的检查和拉取，
+// ctx 被取消或超时后会中断正在进行的拉取。
+func NewContext(ctx context.Context, opts ...Option) (*OllamaLLM, error) {
 
 	// 初始化 llm 这里 llm 时 OllamaLLM* llm
 	llm := &OllamaLLM{
-		model: ollamaclient.DefaultChatModel,
+		model:  ollamaclient.DefaultChatModel,
+		runner: runner.New("ollama"),
 	}
 
 	// 循环 function(*llm) 然后在函数内部去更新 llm
@@ -29,11 +63,17 @@ func New(opts ...Option) (*OllamaLLM, error) {
 	}
 
 	// 初始化 client
-	client, err := ollamaclient.New("")
+	client, err := ollamaclient.New("", llm.clientOptions...)
 	if err != nil {
 		return nil, err
 	}
 	llm.client = client
+
+	if llm.autoPull {
+		if err := llm.EnsureModels(ctx); err != nil {
+			return nil, err
+		}
+	}
 	return llm, nil
 }
 
@@ -45,78 +85,306 @@ func WithModel(model string) Option {
 	}
 }
 
+// WithEmbeddingModel 指定 CreateEmbedding 使用的模型，例如 "nomic-embed-text"。
+// 未指定时使用 WithModel 设置的聊天模型。
+func WithEmbeddingModel(model string) Option {
+	return func(llm *OllamaLLM) {
+		llm.embeddingModel = model
+	}
+}
+
+// WithBaseURL 指定Ollama服务的基础URL，未指定时读取 OLLAMA_HOST 环境变量，默认为 http://localhost:11434。
+func WithBaseURL(baseURL string) Option {
+	return func(llm *OllamaLLM) {
+		llm.clientOptions = append(llm.clientOptions, ollamaclient.WithBaseURL(baseURL))
+	}
+}
+
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义 Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(llm *OllamaLLM) {
+		llm.clientOptions = append(llm.clientOptions, ollamaclient.WithHTTPClient(httpClient))
+	}
+}
+
+// WithHeader 为每个请求附加一个自定义请求头。
+func WithHeader(key, value string) Option {
+	return func(llm *OllamaLLM) {
+		llm.clientOptions = append(llm.clientOptions, ollamaclient.WithHeader(key, value))
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间。
+func WithTimeout(timeout time.Duration) Option {
+	return func(llm *OllamaLLM) {
+		llm.clientOptions = append(llm.clientOptions, ollamaclient.WithTimeout(timeout))
+	}
+}
+
+// WithMaxConcurrency 设置 Generate 批量生成时同时进行的最大请求数，默认为 8，小于等于 0 时不限制。
+func WithMaxConcurrency(n int) Option {
+	return func(llm *OllamaLLM) {
+		llm.runner.MaxConcurrency = n
+	}
+}
+
+// WithRateLimit 开启客户端限流，限制每分钟的请求数和 token 数，传入 0 表示不限制该项。
+// 发出请求前按估算的 token 数预留配额，拿到响应后按实际用量修正。
+// 同一个实例上的所有调用共享这一限额。
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) Option {
+	return func(llm *OllamaLLM) {
+		llm.runner.SetRateLimit(requestsPerMinute, tokensPerMinute)
+	}
+}
+
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1 表示不重试，默认为 3。
+// 429、5xx 以及连接错误会按照带随机抖动的指数退避重试。
+func WithMaxAttempts(attempts int) Option {
+	return func(llm *OllamaLLM) {
+		llm.clientOptions = append(llm.clientOptions, ollamaclient.WithMaxAttempts(attempts))
+	}
+}
+
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(llm *OllamaLLM) {
+		llm.clientOptions = append(llm.clientOptions, ollamaclient.WithRetryBackoff(initial, max))
+	}
+}
+
+// WithCallbacks 注册接收这个实例上所有调用事件的 Handler。
+// 只想观察某一次调用时，可以使用 callbacks.WithHandler 把 Handler 放进 context。
+func WithCallbacks(h callbacks.Handler) Option {
+	return func(llm *OllamaLLM) {
+		llm.runner.Callbacks = h
+	}
+}
+
+// WithAutoPull 让 New 和 NewContext 检查聊天模型和向量模型是否已经下载，没有时从镜像仓库拉取，
+// progress 不为nil时接收拉取进度。拉取大模型可能需要很长时间，需要限制等待时间时请使用 NewContext。
+func WithAutoPull(progress func(PullProgress)) Option {
+	return func(llm *OllamaLLM) {
+		llm.autoPull = true
+		llm.pullProgress = progress
+	}
+}
+
 // llm 上方法
-func (l *OllamaLLM) Call(prompt string) (string, error) {
-	ctx := context.Background()
+// Call 是 CallContext 的简单封装，使用 context.Background()。
+func (l *OllamaLLM) Call(prompt string, opts ...llms.CallOption) (string, error) {
+	return l.CallContext(context.Background(), prompt, opts...)
+}
 
+// CallContext 使用调用方传入的 ctx 向模型发送单个提示，
+// ctx 被取消或超时后，正在进行的 HTTP 请求会随之中断。
+func (l *OllamaLLM) CallContext(ctx context.Context, prompt string, opts ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, l, prompt, opts...)
+}
+
+// GenerateContent 发送一组多轮对话消息（系统提示、历史回复等），
+// 消息会被映射为 ollamaclient.ChatRequest 中的 Messages。
+func (l *OllamaLLM) GenerateContent(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error) {
+	return l.runner.GenerateContent(ctx, messages, opts, l.generateContent)
+}
+
+func (l *OllamaLLM) generateContent(ctx context.Context, messages []llms.Message, callOpts *llms.CallOptions) (*llms.ContentResponse, error) {
 	// 构建聊天请求
 	req := &ollamaclient.ChatRequest{
-		Model: l.model, //当前llma
-		Messages: []ollamaclient.Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Stream: false,
+		Model:         l.modelFor(callOpts), //当前llma
+		Messages:      toClientMessages(messages),
+		Stream:        callOpts.StreamingFunc != nil,
+		StreamingFunc: callOpts.StreamingFunc,
+		Options:       toClientOptions(callOpts),
+		Tools:         toClientTools(callOpts.Tools), // Ollama 不支持 tool_choice
+		Format:        toClientFormat(callOpts),
 	}
 
+	start := time.Now()
 	resp, err := l.client.Chat(ctx, req)
 	if err != nil {
-		return "", fmt.Errorf("ollama Chat failed: %w", err)
+		return nil, fmt.Errorf("ollama Chat failed: %w", err)
 	}
 
-	return resp.Content, nil
+	// Ollama 在请求工具调用时仍然返回 done_reason "stop"，这里统一为 tool_calls。
+	toolCalls := fromClientToolCalls(resp.ToolCalls)
+	finishReason := resp.DoneReason
+	if len(toolCalls) > 0 && finishReason == llms.FinishReasonStop {
+		finishReason = llms.FinishReasonToolCalls
+	}
+
+	return &llms.ContentResponse{
+		Content:      resp.Content,
+		Model:        resp.Model,
+		FinishReason: finishReason,
+		ToolCalls:    toolCalls,
+		Usage: llms.Usage{
+			PromptTokens:     resp.PromptEvalCount,
+			CompletionTokens: resp.EvalCount,
+			TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+		},
+		Timings: llms.Timings{
+			Latency:    time.Since(start),
+			Total:      time.Duration(resp.TotalDuration),
+			Load:       time.Duration(resp.LoadDuration),
+			PromptEval: time.Duration(resp.PromptEvalDuration),
+			Eval:       time.Duration(resp.EvalDuration),
+		},
+		GenerationInfo: map[string]any{
+			"created_at":           resp.CreatedAt,
+			"done_reason":          resp.DoneReason,
+			"total_duration":       resp.TotalDuration,
+			"load_duration":        resp.LoadDuration,
+			"prompt_eval_count":    resp.PromptEvalCount,
+			"prompt_eval_duration": resp.PromptEvalDuration,
+			"eval_count":           resp.EvalCount,
+			"eval_duration":        resp.EvalDuration,
+		},
+	}, nil
+}
+
+// Generate 是 GenerateContext 的简单封装，使用 context.Background()。
+func (l *OllamaLLM) Generate(prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return l.GenerateContext(context.Background(), prompts, opts...)
+}
+
+// GenerateContext 为每一个提示调用 GenerateContent，所有请求共享同一个 ctx。
+// 同时进行的请求数受 WithMaxConcurrency 限制。
+// 部分提示失败时仍然返回其余提示的结果（失败的位置为空字符串），同时返回 *llms.BatchError。
+func (l *OllamaLLM) GenerateContext(ctx context.Context, prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return llms.BatchCompletions(l.GenerateBatch(ctx, prompts, opts...))
 }
 
-func (l *OllamaLLM) Generate(prompts []string) ([]string, error) {
-	// 用于存储所有完成的文本
-	completions := make([]string, len(prompts))
-	// WaitGroup 用于等待所有 Goroutine 完成
-	var wg sync.WaitGroup
-	// 缓冲通道，用于收集并发 Goroutine 中的错误
-	errs := make(chan error, len(prompts))
+// GenerateBatch 并发地为每一个提示生成结果，并按输入顺序返回每个提示各自的成功或失败。
+func (l *OllamaLLM) GenerateBatch(ctx context.Context, prompts []string, opts ...llms.CallOption) []llms.BatchResult {
+	return l.runner.GenerateBatch(ctx, prompts, opts, l.GenerateContent)
+}
 
-	for i, prompt := range prompts {
-		wg.Add(1) // 增加 WaitGroup 计数器
+// CreateEmbedding 通过 Ollama 的 /api/embed 为一组文本生成向量，实现 embeddings.EmbedderClient。
+func (l *OllamaLLM) CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error) {
+	model := l.embeddingModel
+	if model == "" {
+		model = l.model
+	}
+	resp, err := l.client.Embed(ctx, &ollamaclient.EmbedRequest{
+		Model: model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ollama Embed failed: %w", err)
+	}
+	return resp.Embeddings, nil
+}
 
-		// 启动一个新的 Goroutine
-		go func(i int, p string) {
-			defer wg.Done() // Goroutine 完成时，减少 WaitGroup 计数器
-			ctx := context.Background()
+// modelFor 返回本次调用实际使用的模型名称，调用选项优先于实例配置。
+func (l *OllamaLLM) modelFor(opts *llms.CallOptions) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return l.model
+}
 
-			req := &ollamaclient.ChatRequest{
-				Model: l.model,
-				Messages: []ollamaclient.Message{
-					{
-						Role:    "user",
-						Content: p,
-					},
-				},
-				Stream: false,
-			}
+// toClientFormat 根据 JSON 模式选项生成 Ollama 的 format 字段：
+// 设置了 JSONSchema 时直接发送 schema 对象，只开启 JSONMode 时为 "json"。
+func toClientFormat(opts *llms.CallOptions) any {
+	switch {
+	case opts.JSONSchema != nil:
+		return opts.JSONSchema
+	case opts.JSONMode:
+		return "json"
+	}
+	return nil
+}
 
-			resp, err := l.client.Chat(ctx, req)
-			if err != nil {
-				errs <- fmt.Errorf("ollama Generate for prompt %d failed: %w", i, err)
-				return
+// toClientMessages 把与供应商无关的消息转换为 ollamaclient 的消息结构。
+func toClientMessages(messages []llms.Message) []ollamaclient.Message {
+	out := make([]ollamaclient.Message, 0, len(messages))
+	for _, m := range messages {
+		msg := ollamaclient.Message{
+			Role:     string(m.Role),
+			Content:  m.Content,
+			ToolName: m.Name,
+		}
+		for _, tc := range m.ToolCalls {
+			if tc.FunctionCall == nil {
+				continue
 			}
-			completions[i] = resp.Content
-		}(i, prompt)
+			msg.ToolCalls = append(msg.ToolCalls, ollamaclient.ToolCall{
+				Function: ollamaclient.FunctionCall{
+					Name:      tc.FunctionCall.Name,
+					Arguments: toRawArguments(tc.FunctionCall.Arguments),
+				},
+			})
+		}
+		out = append(out, msg)
 	}
+	return out
+}
 
-	wg.Wait()   // 等待所有 Goroutine 完成执行
-	close(errs) // 关闭错误通道，表示不会再有错误发送
+// toRawArguments 把JSON字符串形式的参数转换为Ollama需要的JSON对象。
+func toRawArguments(arguments string) json.RawMessage {
+	if strings.TrimSpace(arguments) == "" {
+		return json.RawMessage("{}")
+	}
+	if json.Valid([]byte(arguments)) {
+		return json.RawMessage(arguments)
+	}
+	// 参数不是合法的JSON时按字符串发送，避免整个请求序列化失败。
+	raw, _ := json.Marshal(arguments)
+	return raw
+}
 
-	var allErrors []error
-	// 从错误通道中收集所有错误
-	for err := range errs {
-		allErrors = append(allErrors, err)
+// toClientTools 把与供应商无关的工具定义转换为Ollama的工具结构。
+func toClientTools(tools []llms.Tool) []ollamaclient.Tool {
+	var out []ollamaclient.Tool
+	for _, t := range tools {
+		if t.Function == nil {
+			continue
+		}
+		out = append(out, ollamaclient.Tool{
+			Type: llms.ToolTypeFunction,
+			Function: ollamaclient.FunctionDefinition{
+				Name:        t.Function.Name,
+				Description: t.Function.Description,
+				Parameters:  t.Function.Parameters,
+			},
+		})
 	}
+	return out
+}
 
-	if len(allErrors) > 0 {
-		return nil, fmt.Errorf("multiple errors during ollama Generate: %v", allErrors)
+// fromClientToolCalls 把Ollama返回的工具调用转换为与供应商无关的结构。
+// Ollama不返回调用ID，这里按顺序生成 "call_0"、"call_1" 等ID，以便回传工具结果。
+func fromClientToolCalls(calls []ollamaclient.ToolCall) []llms.ToolCall {
+	var out []llms.ToolCall
+	for i, tc := range calls {
+		out = append(out, llms.ToolCall{
+			ID:   fmt.Sprintf("call_%d", i),
+			Type: llms.ToolTypeFunction,
+			FunctionCall: &llms.FunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: string(tc.Function.Arguments),
+			},
+		})
 	}
+	return out
+}
 
-	return completions, nil
+// toClientOptions 把调用选项中的采样参数转换为Ollama请求的options对象，
+// 没有设置任何参数时返回nil。
+func toClientOptions(opts *llms.CallOptions) *ollamaclient.Options {
+	if opts.Temperature == nil && opts.TopP == nil && opts.TopK == nil &&
+		opts.MaxTokens == nil && len(opts.StopWords) == 0 && opts.Seed == nil &&
+		opts.PresencePenalty == nil && opts.FrequencyPenalty == nil {
+		return nil
+	}
+	return &ollamaclient.Options{
+		Temperature:      opts.Temperature,
+		TopP:             opts.TopP,
+		TopK:             opts.TopK,
+		NumPredict:       opts.MaxTokens,
+		Stop:             opts.StopWords,
+		Seed:             opts.Seed,
+		PresencePenalty:  opts.PresencePenalty,
+		FrequencyPenalty: opts.FrequencyPenalty,
+	}
 }
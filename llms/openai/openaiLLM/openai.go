@@ -0,0 +1,356 @@
+package openaiLLM
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/zideajang/langChaingo/callbacks"
+	"github.com/zideajang/langChaingo/embeddings"
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/internal/openaiclient"
+	"github.com/zideajang/langChaingo/llms/internal/runner"
+)
+
+const (
+	// DefaultChatModel 是默认的聊天模型。
+	DefaultChatModel = "gpt-4o-mini"
+	// DefaultEmbeddingModel 是 CreateEmbedding 默认使用的模型。
+	DefaultEmbeddingModel = "text-embedding-3-small"
+	// DefaultBaseURL 是 OpenAI 服务的默认基础URL。
+	DefaultBaseURL = "https://api.openai.com/v1"
+	// DefaultAzureAPIVersion 是 Azure OpenAI 默认使用的 api-version。
+	DefaultAzureAPIVersion = "2024-10-21"
+
+	// apiKeyEnvVar 和 azureAPIKeyEnvVar 是保存 API Key 的环境变量名称。
+	apiKeyEnvVar      = "OPENAI_API_KEY"
+	azureAPIKeyEnvVar = "AZURE_OPENAI_API_KEY"
+)
+
+// ErrAPIKeyNotFound 表示既没有通过 WithToken 指定 API Key，环境变量中也没有找到。
+var ErrAPIKeyNotFound = errors.New("OpenAI API Key not found")
+
+// OpenAILLM 结构体封装了 OpenAI（或 Azure OpenAI）客户端和模型配置。
+type OpenAILLM struct {
+	client         *openaiclient.Client
+	model          string // 聊天模型名称，使用 Azure 时由部署决定，这里只作为请求中的 model 字段
+	embeddingModel string // CreateEmbedding 使用的模型
+
+	token        string // API 密钥，为空时从环境变量读取
+	baseURL      string
+	organization string // 通过 OpenAI-Organization 请求头发送
+	project      string // 通过 OpenAI-Project 请求头发送
+	azure        *azureConfig
+
+	// clientOptions 在创建内部客户端时传给 openaiclient.New
+	clientOptions []openaiclient.Option
+
+	// runner 负责回调、客户端限流和批量生成
+	runner runner.Runner
+}
+
+// azureConfig 保存 Azure OpenAI 部署式URL需要的信息。
+type azureConfig struct {
+	endpoint            string // 例如 https://my-resource.openai.azure.com
+	deployment          string // 聊天模型的部署名称
+	embeddingDeployment string // 向量模型的部署名称
+	apiVersion          string
+}
+
+var (
+	_ llms.ContextLLM = (*OpenAILLM)(nil)
+	_ llms.ChatModel  = (*OpenAILLM)(nil)
+	_ llms.BatchLLM   = (*OpenAILLM)(nil)
+
+	_ embeddings.EmbedderClient = (*OpenAILLM)(nil)
+)
+
+// Option 类型定义了用于配置 OpenAILLM 实例的函数选项。
+type Option func(*OpenAILLM)
+
+// New 创建一个 OpenAILLM 实例。
+// API 密钥按以下顺序查找：WithToken 选项、OPENAI_API_KEY 环境变量；
+// 使用 Azure 时先查找 AZURE_OPENAI_API_KEY。
+func New(opts ...Option) (*OpenAILLM, error) {
+	llm := &OpenAILLM{
+		model:          DefaultChatModel,
+		embeddingModel: DefaultEmbeddingModel,
+		baseURL:        DefaultBaseURL,
+		runner:         runner.New(""), // 供应商名称在创建客户端之后设置
+	}
+	for _, opt := range opts {
+		opt(llm)
+	}
+	if llm.azure != nil && llm.azure.deployment == "" {
+		return nil, errors.New("Azure OpenAI deployment is required")
+	}
+
+	token, err := llm.resolveToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// 默认值放在最前面，调用方的选项可以覆盖它们。
+	clientOpts := []openaiclient.Option{
+		openaiclient.WithProviderName("OpenAI"),
+		openaiclient.WithAPIKey(token),
+		openaiclient.WithStreamUsage(true),
+	}
+	if az := llm.azure; az != nil {
+		// Azure 的模型由部署决定，聊天和向量各自使用自己的部署路径。
+		deployments := "/openai/deployments/"
+		clientOpts = append(clientOpts,
+			openaiclient.WithProviderName("Azure OpenAI"),
+			openaiclient.WithBaseURL(az.endpoint),
+			openaiclient.WithChatPath(deployments+url.PathEscape(az.deployment)+"/chat/completions"),
+			openaiclient.WithEmbeddingsPath(deployments+url.PathEscape(az.embeddingDeployment)+"/embeddings"),
+			openaiclient.WithQueryParam("api-version", az.apiVersion),
+			openaiclient.WithAuthHeader("api-key", ""),
+		)
+	} else {
+		clientOpts = append(clientOpts, openaiclient.WithBaseURL(llm.baseURL))
+	}
+	if llm.organization != "" {
+		clientOpts = append(clientOpts, openaiclient.WithHeader("OpenAI-Organization", llm.organization))
+	}
+	if llm.project != "" {
+		clientOpts = append(clientOpts, openaiclient.WithHeader("OpenAI-Project", llm.project))
+	}
+
+	client, err := openaiclient.New(append(clientOpts, llm.clientOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenAI client: %w", err)
+	}
+	llm.client = client
+	llm.runner.Provider = client.ProviderName()
+	return llm, nil
+}
+
+// resolveToken 按照 显式传入的token -> 环境变量 的顺序查找API密钥。
+func (l *OpenAILLM) resolveToken() (string, error) {
+	if l.token != "" {
+		return l.token, nil
+	}
+	envVars := []string{apiKeyEnvVar}
+	if l.azure != nil {
+		envVars = []string{azureAPIKeyEnvVar, apiKeyEnvVar}
+	}
+	for _, name := range envVars {
+		if token := os.Getenv(name); token != "" {
+			return token, nil
+		}
+	}
+	return "", fmt.Errorf("%w: tried WithToken and environment variables %v", ErrAPIKeyNotFound, envVars)
+}
+
+// WithModel 设置聊天模型名称，默认为 DefaultChatModel。
+func WithModel(model string) Option {
+	return func(llm *OpenAILLM) {
+		llm.model = model
+	}
+}
+
+// WithEmbeddingModel 指定 CreateEmbedding 使用的模型，默认为 DefaultEmbeddingModel。
+func WithEmbeddingModel(model string) Option {
+	return func(llm *OpenAILLM) {
+		llm.embeddingModel = model
+	}
+}
+
+// WithToken 直接指定 API 密钥，优先于环境变量。
+func WithToken(token string) Option {
+	return func(llm *OpenAILLM) {
+		llm.token = token
+	}
+}
+
+// WithBaseURL 指定服务的基础URL，默认为 DefaultBaseURL，可用于接入代理或网关。
+// 使用 WithAzure 时忽略该选项。
+func WithBaseURL(baseURL string) Option {
+	return func(llm *OpenAILLM) {
+		llm.baseURL = baseURL
+	}
+}
+
+// WithOrganization 设置请求所属的组织，通过 OpenAI-Organization 请求头发送。
+func WithOrganization(organization string) Option {
+	return func(llm *OpenAILLM) {
+		llm.organization = organization
+	}
+}
+
+// WithProject 设置请求所属的项目，通过 OpenAI-Project 请求头发送。
+func WithProject(project string) Option {
+	return func(llm *OpenAILLM) {
+		llm.project = project
+	}
+}
+
+// WithAzure 改为调用 Azure OpenAI，请求发送到
+// {endpoint}/openai/deployments/{deployment}/chat/completions?api-version=...，
+// 并使用 api-key 请求头认证。endpoint 形如 https://my-resource.openai.azure.com，
+// deployment 为空时 New 返回错误。
+func WithAzure(endpoint, deployment string) Option {
+	return func(llm *OpenAILLM) {
+		llm.azureConfig().endpoint = endpoint
+		llm.azureConfig().deployment = deployment
+	}
+}
+
+// WithAzureEmbeddingDeployment 设置 Azure OpenAI 上 CreateEmbedding 使用的部署名称。
+func WithAzureEmbeddingDeployment(deployment string) Option {
+	return func(llm *OpenAILLM) {
+		llm.azureConfig().embeddingDeployment = deployment
+	}
+}
+
+// WithAPIVersion 设置 Azure OpenAI 的 api-version 查询参数，默认为 DefaultAzureAPIVersion。
+func WithAPIVersion(version string) Option {
+	return func(llm *OpenAILLM) {
+		llm.azureConfig().apiVersion = version
+	}
+}
+
+// azureConfig 返回 Azure 配置，第一次调用时创建。
+func (l *OpenAILLM) azureConfig() *azureConfig {
+	if l.azure == nil {
+		l.azure = &azureConfig{apiVersion: DefaultAzureAPIVersion}
+	}
+	return l.azure
+}
+
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义 Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(llm *OpenAILLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithHTTPClient(httpClient))
+	}
+}
+
+// WithHeader 为每个请求附加一个自定义请求头。
+func WithHeader(key, value string) Option {
+	return func(llm *OpenAILLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithHeader(key, value))
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间。
+func WithTimeout(timeout time.Duration) Option {
+	return func(llm *OpenAILLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithTimeout(timeout))
+	}
+}
+
+// WithStreamUsage 设置流式请求是否发送 stream_options.include_usage，让响应带上 token 用量，默认开启。
+// 不支持这个字段的网关或旧版本的 Azure api-version 可以传入 false 关闭。
+func WithStreamUsage(enabled bool) Option {
+	return func(llm *OpenAILLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithStreamUsage(enabled))
+	}
+}
+
+// WithMaxConcurrency 设置 Generate 批量生成时同时进行的最大请求数，默认为 8，小于等于 0 时不限制。
+func WithMaxConcurrency(n int) Option {
+	return func(llm *OpenAILLM) {
+		llm.runner.MaxConcurrency = n
+	}
+}
+
+// WithRateLimit 开启客户端限流，限制每分钟的请求数和 token 数，传入 0 表示不限制该项。
+// 同一个实例上的所有调用共享这一限额。
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) Option {
+	return func(llm *OpenAILLM) {
+		llm.runner.SetRateLimit(requestsPerMinute, tokensPerMinute)
+	}
+}
+
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1 表示不重试，默认为 3。
+func WithMaxAttempts(attempts int) Option {
+	return func(llm *OpenAILLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithMaxAttempts(attempts))
+	}
+}
+
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(llm *OpenAILLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithRetryBackoff(initial, max))
+	}
+}
+
+// WithCallbacks 注册接收这个实例上所有调用事件的 Handler。
+func WithCallbacks(h callbacks.Handler) Option {
+	return func(llm *OpenAILLM) {
+		llm.runner.Callbacks = h
+	}
+}
+
+// Call 方法实现了 llms.LLM 接口的 Call 方法，是 CallContext 的简单封装。
+func (l *OpenAILLM) Call(prompt string, opts ...llms.CallOption) (string, error) {
+	return l.CallContext(context.Background(), prompt, opts...)
+}
+
+// CallContext 使用调用方传入的 ctx 向 OpenAI 模型发送单个提示。
+func (l *OpenAILLM) CallContext(ctx context.Context, prompt string, opts ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, l, prompt, opts...)
+}
+
+// GenerateContent 发送一组多轮对话消息（系统提示、历史回复等）。
+// OpenAI 支持 json_schema，llms.WithJSONSchema 的 schema 会随请求发送。
+func (l *OpenAILLM) GenerateContent(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error) {
+	return l.runner.GenerateContent(ctx, messages, opts, l.generateContent)
+}
+
+func (l *OpenAILLM) generateContent(ctx context.Context, messages []llms.Message, callOpts *llms.CallOptions) (*llms.ContentResponse, error) {
+	model := l.model
+	if callOpts.Model != "" {
+		model = callOpts.Model
+	}
+	req := openaiclient.NewChatRequest(model, messages, callOpts, true)
+
+	start := time.Now()
+	resp, err := l.client.Chat(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s Chat failed: %w", l.client.ProviderName(), err)
+	}
+	return resp.ContentResponse(time.Since(start)), nil
+}
+
+// Generate 方法实现了 llms.LLM 接口的 Generate 方法，是 GenerateContext 的简单封装。
+func (l *OpenAILLM) Generate(prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return l.GenerateContext(context.Background(), prompts, opts...)
+}
+
+// GenerateContext 为每一个提示调用 GenerateContent，同时进行的请求数受 WithMaxConcurrency 限制。
+// 部分提示失败时仍然返回其余提示的结果（失败的位置为空字符串），同时返回 *llms.BatchError。
+func (l *OpenAILLM) GenerateContext(ctx context.Context, prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return llms.BatchCompletions(l.GenerateBatch(ctx, prompts, opts...))
+}
+
+// GenerateBatch 并发地为每一个提示生成结果，并按输入顺序返回每个提示各自的成功或失败。
+func (l *OpenAILLM) GenerateBatch(ctx context.Context, prompts []string, opts ...llms.CallOption) []llms.BatchResult {
+	return l.runner.GenerateBatch(ctx, prompts, opts, l.GenerateContent)
+}
+
+// CreateEmbedding 通过 /embeddings 为一组文本生成向量，实现 embeddings.EmbedderClient。
+// 使用 Azure 时需要通过 WithAzureEmbeddingDeployment 指定向量模型的部署。
+func (l *OpenAILLM) CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error) {
+	if l.azure != nil && l.azure.embeddingDeployment == "" {
+		return nil, errors.New("Azure OpenAI embeddings require WithAzureEmbeddingDeployment")
+	}
+	resp, err := l.client.CreateEmbeddings(ctx, &openaiclient.EmbeddingRequest{
+		Model: l.embeddingModel,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s CreateEmbeddings failed: %w", l.client.ProviderName(), err)
+	}
+	out := make([][]float32, len(resp.Data))
+	for i, d := range resp.Data {
+		out[i] = d.Embedding
+	}
+	return out, nil
+}
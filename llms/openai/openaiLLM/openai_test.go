@@ -0,0 +1,200 @@
+package openaiLLM
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// fakeOpenAI 是模仿 OpenAI 响应格式的测试服务，记录最后一次请求。
+type fakeOpenAI struct {
+	*httptest.Server
+	req  *http.Request
+	body map[string]any
+}
+
+func newFakeOpenAI(t *testing.T) *fakeOpenAI {
+	t.Helper()
+	f := &fakeOpenAI{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		f.req = r
+		f.body = nil
+		if err := json.Unmarshal(b, &f.body); err != nil {
+			t.Errorf("invalid request body %s: %v", b, err)
+		}
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/embeddings"):
+			io.WriteString(w, `{"object":"list","model":"text-embedding-3-small",
+				"data":[{"object":"embedding","index":1,"embedding":[0.3,0.4]},{"object":"embedding","index":0,"embedding":[0.1,0.2]}],
+				"usage":{"prompt_tokens":4,"total_tokens":4}}`)
+		case f.body["stream"] == true:
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `data: {"id":"chatcmpl-2","object":"chat.completion.chunk","created":1,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}
+
+data: {"id":"chatcmpl-2","object":"chat.completion.chunk","created":1,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}
+
+data: {"id":"chatcmpl-2","object":"chat.completion.chunk","created":1,"model":"gpt-4o-mini","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}
+
+data: [DONE]
+
+`)
+		default:
+			io.WriteString(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1700000000,"model":"gpt-4o-mini-2024-07-18",
+				"choices":[{"index":0,"message":{"role":"assistant","content":"Hello!"},"finish_reason":"stop"}],
+				"usage":{"prompt_tokens":9,"completion_tokens":3,"total_tokens":12}}`)
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestGenerateContent(t *testing.T) {
+	srv := newFakeOpenAI(t)
+	llm, err := New(
+		WithToken("sk-test"),
+		WithBaseURL(srv.URL+"/v1"),
+		WithOrganization("org-123"),
+		WithProject("proj_456"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := llm.GenerateContent(context.Background(), []llms.Message{
+		llms.SystemMessage("be brief"),
+		llms.UserMessage("hi"),
+	}, llms.WithTemperature(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Content != "Hello!" || resp.ID != "chatcmpl-1" || resp.Model != "gpt-4o-mini-2024-07-18" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if resp.FinishReason != llms.FinishReasonStop {
+		t.Errorf("FinishReason = %q", resp.FinishReason)
+	}
+	if want := (llms.Usage{PromptTokens: 9, CompletionTokens: 3, TotalTokens: 12}); resp.Usage != want {
+		t.Errorf("Usage = %+v, want %+v", resp.Usage, want)
+	}
+
+	if got := srv.req.URL.Path; got != "/v1/chat/completions" {
+		t.Errorf("path = %q", got)
+	}
+	for header, want := range map[string]string{
+		"Authorization":       "Bearer sk-test",
+		"OpenAI-Organization": "org-123",
+		"OpenAI-Project":      "proj_456",
+	} {
+		if got := srv.req.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if srv.body["model"] != DefaultChatModel || srv.body["temperature"] != 0.5 {
+		t.Errorf("unexpected request body %v", srv.body)
+	}
+}
+
+func TestAzure(t *testing.T) {
+	srv := newFakeOpenAI(t)
+	llm, err := New(
+		WithToken("azure-key"),
+		WithAzure(srv.URL, "gpt4o-prod"),
+		WithAzureEmbeddingDeployment("embed-prod"),
+		WithAPIVersion("2024-06-01"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := llm.Call("hi"); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.req.URL.Path; got != "/openai/deployments/gpt4o-prod/chat/completions" {
+		t.Errorf("path = %q", got)
+	}
+	if got := srv.req.URL.Query().Get("api-version"); got != "2024-06-01" {
+		t.Errorf("api-version = %q", got)
+	}
+	if got := srv.req.Header.Get("api-key"); got != "azure-key" {
+		t.Errorf("api-key = %q", got)
+	}
+	if got := srv.req.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization should not be sent to Azure, got %q", got)
+	}
+
+	if _, err := llm.CreateEmbedding(context.Background(), []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.req.URL.Path; got != "/openai/deployments/embed-prod/embeddings" {
+		t.Errorf("embeddings path = %q", got)
+	}
+}
+
+func TestAzureRequiresDeployment(t *testing.T) {
+	if _, err := New(WithToken("k"), WithAzure("https://example.openai.azure.com", "")); err == nil {
+		t.Fatal("expected error for empty Azure deployment")
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	srv := newFakeOpenAI(t)
+	llm, err := New(WithToken("sk-test"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chunks []string
+	resp, err := llm.GenerateContent(context.Background(), []llms.Message{llms.UserMessage("hi")},
+		llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			chunks = append(chunks, string(chunk))
+			return nil
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(chunks, []string{"Hel", "lo"}) {
+		t.Errorf("chunks = %q", chunks)
+	}
+	if resp.Content != "Hello" || resp.FinishReason != llms.FinishReasonStop || resp.Usage.TotalTokens != 7 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	opts, _ := srv.body["stream_options"].(map[string]any)
+	if opts["include_usage"] != true {
+		t.Errorf("stream_options = %v", srv.body["stream_options"])
+	}
+}
+
+func TestCreateEmbedding(t *testing.T) {
+	srv := newFakeOpenAI(t)
+	llm, err := New(WithToken("sk-test"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vectors, err := llm.CreateEmbedding(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 响应中的向量顺序被打乱，结果应当按 index 与输入对应。
+	want := [][]float32{{0.1, 0.2}, {0.3, 0.4}}
+	if !reflect.DeepEqual(vectors, want) {
+		t.Errorf("vectors = %v, want %v", vectors, want)
+	}
+	if got := srv.req.URL.Path; got != "/embeddings" {
+		t.Errorf("path = %q", got)
+	}
+	if srv.body["model"] != DefaultEmbeddingModel {
+		t.Errorf("model = %v", srv.body["model"])
+	}
+}
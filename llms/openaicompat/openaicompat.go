@@ -0,0 +1,232 @@
+// Package openaicompat 接入任意兼容 OpenAI chat completions 接口的服务，
+// 例如 vLLM、LM Studio、llama.cpp server、Moonshot、通义千问 DashScope 兼容模式等。
+//
+//	llm, err := openaicompat.New(
+//		openaicompat.WithBaseURL("http://localhost:8000/v1"),
+//		openaicompat.WithModel("Qwen/Qwen2.5-7B-Instruct"),
+//	)
+package openaicompat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zideajang/langChaingo/callbacks"
+	"github.com/zideajang/langChaingo/llms"
+	"github.com/zideajang/langChaingo/llms/internal/openaiclient"
+	"github.com/zideajang/langChaingo/llms/internal/runner"
+)
+
+// LLM 封装了 OpenAI 兼容接口的客户端和模型配置。
+type LLM struct {
+	client *openaiclient.Client
+	model  string // 模型名称，为空时请求中不携带 model 字段
+
+	// clientOptions 在创建内部客户端时传给 openaiclient.New
+	clientOptions []openaiclient.Option
+
+	// jsonSchema 表示服务支持 response_format 的 json_schema 类型
+	jsonSchema bool
+
+	// runner 负责回调、客户端限流和批量生成
+	runner runner.Runner
+}
+
+var (
+	_ llms.ContextLLM = (*LLM)(nil)
+	_ llms.ChatModel  = (*LLM)(nil)
+	_ llms.BatchLLM   = (*LLM)(nil)
+)
+
+// Option 类型定义了用于配置 LLM 实例的函数选项。
+type Option func(*LLM)
+
+// New 创建一个 LLM 实例，必须通过 WithBaseURL 指定服务地址。
+func New(opts ...Option) (*LLM, error) {
+	llm := &LLM{
+		runner: runner.New(""), // 供应商名称在创建客户端之后设置
+	}
+	for _, opt := range opts {
+		opt(llm)
+	}
+
+	client, err := openaiclient.New(llm.clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenAI-compatible client: %w", err)
+	}
+	llm.client = client
+	llm.runner.Provider = client.ProviderName()
+	return llm, nil
+}
+
+// WithModel 设置模型名称。只部署了一个模型的本地服务可以不设置。
+func WithModel(model string) Option {
+	return func(llm *LLM) {
+		llm.model = model
+	}
+}
+
+// WithBaseURL 指定服务的基础URL，需要包含路径前缀，
+// 例如 "http://localhost:8000/v1" 或 "https://dashscope.aliyuncs.com/compatible-mode/v1"。
+func WithBaseURL(baseURL string) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithBaseURL(baseURL))
+	}
+}
+
+// WithChatPath 指定聊天补全接口相对于基础URL的路由，默认为 "/chat/completions"。
+func WithChatPath(path string) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithChatPath(path))
+	}
+}
+
+// WithAPIKey 设置 API 密钥，不设置时请求中不携带认证请求头。
+func WithAPIKey(apikey string) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithAPIKey(apikey))
+	}
+}
+
+// WithAuthHeader 设置认证请求头的名称和值前缀，默认为 ("Authorization", "Bearer ")。
+// 例如要求 "api-key: <key>" 的服务可以传入 ("api-key", "")。
+func WithAuthHeader(name, prefix string) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithAuthHeader(name, prefix))
+	}
+}
+
+// WithQueryParam 为每个请求的URL附加一个查询参数。
+func WithQueryParam(key, value string) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithQueryParam(key, value))
+	}
+}
+
+// WithProviderName 设置错误信息和 llms.APIError 中使用的供应商名称，默认为 "OpenAI-compatible"。
+func WithProviderName(name string) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithProviderName(name))
+	}
+}
+
+// WithJSONSchemaSupport 表示服务支持 response_format 的 json_schema 类型，
+// 开启后 llms.WithJSONSchema 的 schema 会随请求发送；默认退化为 json_object 模式。
+func WithJSONSchemaSupport() Option {
+	return func(llm *LLM) {
+		llm.jsonSchema = true
+	}
+}
+
+// WithHTTPClient 指定发送请求使用的 *http.Client，可用于自定义 Transport、代理等。
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithHTTPClient(httpClient))
+	}
+}
+
+// WithHeader 为每个请求附加一个自定义请求头。
+func WithHeader(key, value string) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithHeader(key, value))
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间。
+func WithTimeout(timeout time.Duration) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithTimeout(timeout))
+	}
+}
+
+// WithStreamUsage 设置流式请求是否发送 stream_options.include_usage，让响应带上 token 用量。
+// 默认关闭，因为不是所有兼容服务都认识这个字段；确认服务支持时可以开启。
+func WithStreamUsage(enabled bool) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithStreamUsage(enabled))
+	}
+}
+
+// WithMaxConcurrency 设置 Generate 批量生成时同时进行的最大请求数，默认为 8，小于等于 0 时不限制。
+func WithMaxConcurrency(n int) Option {
+	return func(llm *LLM) {
+		llm.runner.MaxConcurrency = n
+	}
+}
+
+// WithRateLimit 开启客户端限流，限制每分钟的请求数和 token 数，传入 0 表示不限制该项。
+// 同一个实例上的所有调用共享这一限额。
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) Option {
+	return func(llm *LLM) {
+		llm.runner.SetRateLimit(requestsPerMinute, tokensPerMinute)
+	}
+}
+
+// WithMaxAttempts 设置包含第一次请求在内的最大尝试次数，1 表示不重试，默认为 3。
+func WithMaxAttempts(attempts int) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithMaxAttempts(attempts))
+	}
+}
+
+// WithRetryBackoff 设置重试的基础等待时间和最长等待时间。
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(llm *LLM) {
+		llm.clientOptions = append(llm.clientOptions, openaiclient.WithRetryBackoff(initial, max))
+	}
+}
+
+// WithCallbacks 注册接收这个实例上所有调用事件的 Handler。
+func WithCallbacks(h callbacks.Handler) Option {
+	return func(llm *LLM) {
+		llm.runner.Callbacks = h
+	}
+}
+
+// Call 方法实现了 llms.LLM 接口的 Call 方法，是 CallContext 的简单封装。
+func (l *LLM) Call(prompt string, opts ...llms.CallOption) (string, error) {
+	return l.CallContext(context.Background(), prompt, opts...)
+}
+
+// CallContext 使用调用方传入的 ctx 发送单个提示。
+func (l *LLM) CallContext(ctx context.Context, prompt string, opts ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, l, prompt, opts...)
+}
+
+// GenerateContent 发送一组多轮对话消息（系统提示、历史回复等）。
+func (l *LLM) GenerateContent(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error) {
+	return l.runner.GenerateContent(ctx, messages, opts, l.generateContent)
+}
+
+func (l *LLM) generateContent(ctx context.Context, messages []llms.Message, callOpts *llms.CallOptions) (*llms.ContentResponse, error) {
+	model := l.model
+	if callOpts.Model != "" {
+		model = callOpts.Model
+	}
+	req := openaiclient.NewChatRequest(model, messages, callOpts, l.jsonSchema)
+
+	start := time.Now()
+	resp, err := l.client.Chat(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s Chat failed: %w", l.client.ProviderName(), err)
+	}
+	return resp.ContentResponse(time.Since(start)), nil
+}
+
+// Generate 方法实现了 llms.LLM 接口的 Generate 方法，是 GenerateContext 的简单封装。
+func (l *LLM) Generate(prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return l.GenerateContext(context.Background(), prompts, opts...)
+}
+
+// GenerateContext 为每一个提示调用 GenerateContent，同时进行的请求数受 WithMaxConcurrency 限制。
+// 部分提示失败时仍然返回其余提示的结果（失败的位置为空字符串），同时返回 *llms.BatchError。
+func (l *LLM) GenerateContext(ctx context.Context, prompts []string, opts ...llms.CallOption) ([]string, error) {
+	return llms.BatchCompletions(l.GenerateBatch(ctx, prompts, opts...))
+}
+
+// GenerateBatch 并发地为每一个提示生成结果，并按输入顺序返回每个提示各自的成功或失败。
+func (l *LLM) GenerateBatch(ctx context.Context, prompts []string, opts ...llms.CallOption) []llms.BatchResult {
+	return l.runner.GenerateBatch(ctx, prompts, opts, l.GenerateContent)
+}
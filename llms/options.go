@@ -0,0 +1,160 @@
+package llms
+
+import "context"
+
+// CallOption 是用于配置单次调用的函数选项。
+type CallOption func(*CallOptions)
+
+// CallOptions 保存单次调用的全部配置。
+type CallOptions struct {
+	// Model 覆盖 LLM 实例上配置的模型名称，为空时使用实例的默认模型。
+	Model string
+	// StreamingFunc 不为空时以流式方式请求模型，每收到一段增量内容就回调一次。
+	// 回调返回错误会中止本次请求。流结束后仍然返回聚合后的完整响应。
+	StreamingFunc func(ctx context.Context, chunk []byte) error
+
+	// 以下为采样参数，nil 表示不设置，由服务端使用默认值。
+	// 并不是所有供应商都支持全部参数，不支持的参数会被忽略。
+
+	// Temperature 是采样温度，越高输出越随机。
+	Temperature *float64
+	// TopP 是核采样的累计概率阈值。
+	TopP *float64
+	// TopK 只从概率最高的 K 个 token 中采样。
+	TopK *int
+	// MaxTokens 是本次最多生成的 token 数。
+	MaxTokens *int
+	// StopWords 是停止序列，生成到其中任意一个时停止。
+	StopWords []string
+	// Seed 是随机种子，配合固定的温度可以让结果可复现。
+	Seed *int
+	// PresencePenalty 惩罚已经出现过的 token，鼓励谈论新话题。
+	PresencePenalty *float64
+	// FrequencyPenalty 按出现次数惩罚 token，减少重复。
+	FrequencyPenalty *float64
+
+	// Tools 是本次调用中模型可以使用的工具。
+	Tools []Tool
+	// ToolChoice 控制模型是否以及如何调用工具，取值为 ToolChoiceAuto 等字符串或 *ToolChoice。
+	ToolChoice any
+
+	// JSONMode 要求模型只输出合法的 JSON。
+	JSONMode bool
+	// JSONSchema 是期望输出满足的 JSON Schema，设置后隐含 JSONMode。
+	// 支持结构化输出的供应商会把它发给服务端，其余供应商退化为 JSONMode。
+	JSONSchema any
+
+	// CallbackHandler 是只对本次调用生效的 callbacks.Handler，由 callbacks.WithCallHandler 设置。
+	// 为避免 llms 依赖 callbacks 包，这里使用 any 类型。
+	CallbackHandler any
+}
+
+// NewCallOptions 依次应用所有选项并返回最终的调用配置。
+func NewCallOptions(opts ...CallOption) *CallOptions {
+	o := &CallOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithModel 为本次调用指定模型名称。
+func WithModel(model string) CallOption {
+	return func(o *CallOptions) {
+		o.Model = model
+	}
+}
+
+// WithStreamingFunc 为本次调用开启流式输出，fn 会按顺序收到模型生成的每一段内容。
+func WithStreamingFunc(fn func(ctx context.Context, chunk []byte) error) CallOption {
+	return func(o *CallOptions) {
+		o.StreamingFunc = fn
+	}
+}
+
+// WithTemperature 设置采样温度。
+func WithTemperature(temperature float64) CallOption {
+	return func(o *CallOptions) {
+		o.Temperature = &temperature
+	}
+}
+
+// WithTopP 设置核采样的累计概率阈值。
+func WithTopP(topP float64) CallOption {
+	return func(o *CallOptions) {
+		o.TopP = &topP
+	}
+}
+
+// WithTopK 设置只从概率最高的 K 个 token 中采样。
+func WithTopK(topK int) CallOption {
+	return func(o *CallOptions) {
+		o.TopK = &topK
+	}
+}
+
+// WithMaxTokens 设置本次最多生成的 token 数。
+func WithMaxTokens(maxTokens int) CallOption {
+	return func(o *CallOptions) {
+		o.MaxTokens = &maxTokens
+	}
+}
+
+// WithStopWords 设置停止序列。
+func WithStopWords(stopWords ...string) CallOption {
+	return func(o *CallOptions) {
+		o.StopWords = stopWords
+	}
+}
+
+// WithSeed 设置随机种子。
+func WithSeed(seed int) CallOption {
+	return func(o *CallOptions) {
+		o.Seed = &seed
+	}
+}
+
+// WithPresencePenalty 设置存在惩罚。
+func WithPresencePenalty(penalty float64) CallOption {
+	return func(o *CallOptions) {
+		o.PresencePenalty = &penalty
+	}
+}
+
+// WithFrequencyPenalty 设置频率惩罚。
+func WithFrequencyPenalty(penalty float64) CallOption {
+	return func(o *CallOptions) {
+		o.FrequencyPenalty = &penalty
+	}
+}
+
+// WithTools 设置本次调用中模型可以使用的工具。
+func WithTools(tools ...Tool) CallOption {
+	return func(o *CallOptions) {
+		o.Tools = tools
+	}
+}
+
+// WithToolChoice 控制模型是否以及如何调用工具。
+// choice 可以是 ToolChoiceAuto、ToolChoiceNone、ToolChoiceRequired，
+// 也可以是通过 FunctionToolChoice 指定的某个函数。
+func WithToolChoice(choice any) CallOption {
+	return func(o *CallOptions) {
+		o.ToolChoice = choice
+	}
+}
+
+// WithJSONMode 要求模型只输出合法的 JSON。
+func WithJSONMode() CallOption {
+	return func(o *CallOptions) {
+		o.JSONMode = true
+	}
+}
+
+// WithJSONSchema 要求模型输出满足 schema 的 JSON。
+func WithJSONSchema(schema any) CallOption {
+	return func(o *CallOptions) {
+		o.JSONMode = true
+		o.JSONSchema = schema
+	}
+}
@@ -0,0 +1,37 @@
+package llms
+
+import "unicode"
+
+// EstimateTokens 粗略估算一段文本的 token 数，用于限流和上下文预算等不需要精确值的场景。
+// 中日韩字符大约每个字符一个 token，其余字符大约每 4 个字符一个 token。
+func EstimateTokens(text string) int {
+	var cjk, other int
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
+
+// EstimateMessagesTokens 估算一组消息的 token 数，每条消息额外计入少量角色标记的开销。
+func EstimateMessagesTokens(messages []Message) int {
+	const perMessage = 4
+	total := 0
+	for _, m := range messages {
+		total += perMessage + EstimateTokens(m.Content)
+	}
+	return total
+}
+
+// EstimateCallTokens 估算一次调用可能消耗的 token 数：提示部分加上 MaxTokens。
+// 没有设置 MaxTokens 时只计入提示部分。
+func EstimateCallTokens(messages []Message, opts *CallOptions) int {
+	total := EstimateMessagesTokens(messages)
+	if opts != nil && opts.MaxTokens != nil {
+		total += *opts.MaxTokens
+	}
+	return total
+}
@@ -0,0 +1,69 @@
+package llms
+
+// ToolTypeFunction 是目前唯一支持的工具类型。
+const ToolTypeFunction = "function"
+
+// 工具选择模式，用于 WithToolChoice。
+const (
+	// ToolChoiceAuto 由模型自行决定是否调用工具。
+	ToolChoiceAuto = "auto"
+	// ToolChoiceNone 禁止模型调用工具。
+	ToolChoiceNone = "none"
+	// ToolChoiceRequired 要求模型必须调用至少一个工具。
+	ToolChoiceRequired = "required"
+)
+
+// Tool 是与供应商无关的工具定义。
+type Tool struct {
+	Type     string              `json:"type"` // 工具类型，目前只能是 ToolTypeFunction
+	Function *FunctionDefinition `json:"function,omitempty"`
+}
+
+// FunctionDefinition 描述一个可以被模型调用的函数。
+type FunctionDefinition struct {
+	Name        string `json:"name"`                  // 函数名称
+	Description string `json:"description,omitempty"` // 函数用途，模型据此决定何时调用
+	// Parameters 是参数的 JSON Schema，可以是 map[string]any 或任何能序列化为 JSON 的值。
+	Parameters any `json:"parameters,omitempty"`
+}
+
+// FunctionTool 是创建函数工具的便捷方法。
+func FunctionTool(name, description string, parameters any) Tool {
+	return Tool{
+		Type: ToolTypeFunction,
+		Function: &FunctionDefinition{
+			Name:        name,
+			Description: description,
+			Parameters:  parameters,
+		},
+	}
+}
+
+// ToolCall 是模型请求的一次工具调用。
+type ToolCall struct {
+	ID           string        `json:"id"`   // 调用 ID，回传工具结果时需要带上
+	Type         string        `json:"type"` // 工具类型，目前只能是 ToolTypeFunction
+	FunctionCall *FunctionCall `json:"function,omitempty"`
+}
+
+// FunctionCall 是一次函数调用的名称和参数。
+type FunctionCall struct {
+	Name      string `json:"name"`      // 函数名称
+	Arguments string `json:"arguments"` // JSON 编码的参数
+}
+
+// ToolChoice 用于强制模型调用指定的函数。
+type ToolChoice struct {
+	Type     string             `json:"type"`
+	Function *FunctionReference `json:"function,omitempty"`
+}
+
+// FunctionReference 通过名称引用一个函数。
+type FunctionReference struct {
+	Name string `json:"name"`
+}
+
+// FunctionToolChoice 返回强制模型调用名为 name 的函数的 ToolChoice。
+func FunctionToolChoice(name string) *ToolChoice {
+	return &ToolChoice{Type: ToolTypeFunction, Function: &FunctionReference{Name: name}}
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// ConversationBuffer 保存完整的对话历史。
+type ConversationBuffer struct {
+	opts options
+}
+
+var _ Memory = (*ConversationBuffer)(nil)
+
+// NewConversationBuffer 创建一个保存完整对话历史的记忆。
+func NewConversationBuffer(opts ...Option) *ConversationBuffer {
+	return &ConversationBuffer{opts: newOptions(opts)}
+}
+
+func (b *ConversationBuffer) MemoryVariables() []string {
+	return []string{b.opts.memoryKey}
+}
+
+func (b *ConversationBuffer) LoadMemoryVariables(ctx context.Context, _ map[string]any) (map[string]any, error) {
+	messages, err := b.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{b.opts.memoryKey: b.opts.memoryValue(messages)}, nil
+}
+
+func (b *ConversationBuffer) SaveContext(ctx context.Context, inputs, outputs map[string]any) error {
+	turn, err := b.opts.turnMessages(inputs, outputs)
+	if err != nil {
+		return err
+	}
+	return b.opts.chatHistory.AddMessages(ctx, turn...)
+}
+
+func (b *ConversationBuffer) Clear(ctx context.Context) error {
+	return b.opts.chatHistory.Clear(ctx)
+}
+
+// Messages 返回保存的全部消息。
+func (b *ConversationBuffer) Messages(ctx context.Context) ([]llms.Message, error) {
+	messages, err := b.opts.chatHistory.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toLLMMessages(messages), nil
+}
+
+// ChatHistory 返回保存消息的会话历史。
+func (b *ConversationBuffer) ChatHistory() ChatMessageHistory {
+	return b.opts.chatHistory
+}
+
+// ConversationWindowBuffer 只向模型提供最近 K 轮对话。
+type ConversationWindowBuffer struct {
+	*ConversationBuffer
+	k int
+}
+
+var _ Memory = (*ConversationWindowBuffer)(nil)
+
+// NewConversationWindowBuffer 创建一个只提供最近 k 轮对话（2k 条消息）的记忆。
+func NewConversationWindowBuffer(k int, opts ...Option) *ConversationWindowBuffer {
+	return &ConversationWindowBuffer{ConversationBuffer: NewConversationBuffer(opts...), k: k}
+}
+
+func (w *ConversationWindowBuffer) LoadMemoryVariables(ctx context.Context, _ map[string]any) (map[string]any, error) {
+	messages, err := w.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n := 2 * w.k; len(messages) > n {
+		messages = messages[len(messages)-n:]
+	}
+	return map[string]any{w.opts.memoryKey: w.opts.memoryValue(messages)}, nil
+}
+
+// ConversationTokenBuffer 只向模型提供不超过 token 预算的最近消息。
+// token 数由 llms.EstimateMessagesTokens 估算。
+type ConversationTokenBuffer struct {
+	*ConversationBuffer
+	maxTokens int
+}
+
+var _ Memory = (*ConversationTokenBuffer)(nil)
+
+// NewConversationTokenBuffer 创建一个按 token 预算截断历史的记忆，超出预算时丢弃最早的消息。
+func NewConversationTokenBuffer(maxTokens int, opts ...Option) *ConversationTokenBuffer {
+	return &ConversationTokenBuffer{ConversationBuffer: NewConversationBuffer(opts...), maxTokens: maxTokens}
+}
+
+func (t *ConversationTokenBuffer) LoadMemoryVariables(ctx context.Context, _ map[string]any) (map[string]any, error) {
+	messages, err := t.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	messages = trimToTokens(messages, t.maxTokens)
+	return map[string]any{t.opts.memoryKey: t.opts.memoryValue(messages)}, nil
+}
+
+// trimToTokens 丢弃最早的消息，直到剩余消息的估算 token 数不超过 maxTokens。
+func trimToTokens(messages []llms.Message, maxTokens int) []llms.Message {
+	for len(messages) > 0 && llms.EstimateMessagesTokens(messages) > maxTokens {
+		messages = messages[1:]
+	}
+	return messages
+}
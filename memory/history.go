@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// ChatMessage 是保存在 ChatMessageHistory 中的一条消息。
+type ChatMessage struct {
+	llms.Message
+	// CreatedAt 是消息被保存的时间，为零值时会话历史在保存时使用当前时间。
+	CreatedAt time.Time `json:"created_at"`
+	// Usage 是生成这条回复消耗的 token 数，只有模型回复并且调用方提供了用量时才有值。
+	Usage llms.Usage `json:"usage"`
+}
+
+// ChatMessageHistory 保存一个会话的消息，不同的实现决定消息保存在内存、文件还是数据库中。
+type ChatMessageHistory interface {
+	// AddMessages 按顺序追加消息。
+	AddMessages(ctx context.Context, messages ...ChatMessage) error
+	// Messages 按保存的顺序返回全部消息。
+	Messages(ctx context.Context) ([]ChatMessage, error)
+	// Clear 删除会话的全部消息。
+	Clear(ctx context.Context) error
+	// ReplaceMessages 用 messages 原子地替换会话的全部消息，失败时原有消息保持不变。
+	ReplaceMessages(ctx context.Context, messages ...ChatMessage) error
+}
+
+// InMemoryChatHistory 把消息保存在内存中，进程退出后消息会丢失。
+type InMemoryChatHistory struct {
+	mu       sync.Mutex
+	messages []ChatMessage
+}
+
+var _ ChatMessageHistory = (*InMemoryChatHistory)(nil)
+
+// NewInMemoryChatHistory 创建一个保存在内存中的会话历史。
+func NewInMemoryChatHistory() *InMemoryChatHistory {
+	return &InMemoryChatHistory{}
+}
+
+func (h *InMemoryChatHistory) AddMessages(_ context.Context, messages ...ChatMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, withCreatedAt(messages)...)
+	return nil
+}
+
+func (h *InMemoryChatHistory) Messages(context.Context) ([]ChatMessage, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.messages), nil
+}
+
+func (h *InMemoryChatHistory) Clear(context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = nil
+	return nil
+}
+
+func (h *InMemoryChatHistory) ReplaceMessages(_ context.Context, messages ...ChatMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = withCreatedAt(messages)
+	return nil
+}
+
+// withCreatedAt 返回 messages 的副本，CreatedAt 为零值的消息使用当前时间。
+func withCreatedAt(messages []ChatMessage) []ChatMessage {
+	out := slices.Clone(messages)
+	now := time.Now()
+	for i := range out {
+		if out[i].CreatedAt.IsZero() {
+			out[i].CreatedAt = now
+		}
+	}
+	return out
+}
+
+// toLLMMessages 去掉时间和用量，返回可以直接发给模型的消息。
+func toLLMMessages(messages []ChatMessage) []llms.Message {
+	out := make([]llms.Message, len(messages))
+	for i, m := range messages {
+		out[i] = m.Message
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// testMessages 覆盖了所有会被保存的字段。
+func testMessages() []ChatMessage {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []ChatMessage{
+		{Message: llms.UserMessage("现在几点了？"), CreatedAt: created},
+		{
+			Message: llms.Message{Role: llms.RoleAssistant, ToolCalls: []llms.ToolCall{{
+				ID: "call_1", Type: "function",
+				FunctionCall: &llms.FunctionCall{Name: "current_time", Arguments: `{"tz":"UTC"}`},
+			}}},
+			CreatedAt: created.Add(time.Second),
+			Usage:     llms.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		},
+		{Message: llms.Message{Role: llms.RoleTool, Content: "12:00", ToolCallID: "call_1", Name: "current_time"}, CreatedAt: created.Add(2 * time.Second)},
+	}
+}
+
+// testHistory 对任意 ChatMessageHistory 检查追加、读取、替换和清空。
+// newHistory 为同一个存储中的不同会话创建会话历史。
+func testHistory(t *testing.T, newHistory func(sessionID string) ChatMessageHistory) {
+	t.Helper()
+	ctx := context.Background()
+	h := newHistory("alice")
+	other := newHistory("bob")
+
+	if got, err := h.Messages(ctx); err != nil || len(got) != 0 {
+		t.Fatalf("empty history Messages = %v, %v", got, err)
+	}
+
+	want := testMessages()
+	if err := h.AddMessages(ctx, want[:1]...); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.AddMessages(ctx, want[1:]...); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.AddMessages(ctx, ChatMessage{Message: llms.UserMessage("hi"), CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	assertMessages(t, h, want)
+
+	// CreatedAt 为零值时使用保存时的时间。
+	before := time.Now()
+	if err := h.AddMessages(ctx, ChatMessage{Message: llms.AssistantMessage("ok")}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := h.Messages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created := got[len(got)-1].CreatedAt; created.Before(before.Add(-time.Second)) || created.After(time.Now().Add(time.Second)) {
+		t.Errorf("zero CreatedAt was saved as %v, want about %v", created, before)
+	}
+
+	replaced := []ChatMessage{{Message: llms.SystemMessage("summary"), CreatedAt: want[0].CreatedAt}, want[2]}
+	if err := h.ReplaceMessages(ctx, replaced...); err != nil {
+		t.Fatal(err)
+	}
+	assertMessages(t, h, replaced)
+
+	if err := h.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertMessages(t, h, nil)
+
+	// 其他会话的消息不受影响。
+	if got, err := other.Messages(ctx); err != nil || len(got) != 1 || got[0].Content != "hi" {
+		t.Errorf("other session Messages = %v, %v", got, err)
+	}
+}
+
+func assertMessages(t *testing.T, h ChatMessageHistory, want []ChatMessage) {
+	t.Helper()
+	got, err := h.Messages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].CreatedAt.Equal(want[i].CreatedAt) {
+			t.Errorf("message %d CreatedAt = %v, want %v", i, got[i].CreatedAt, want[i].CreatedAt)
+		}
+		g, w := got[i], want[i]
+		g.CreatedAt, w.CreatedAt = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("message %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestInMemoryChatHistory(t *testing.T) {
+	testHistory(t, func(string) ChatMessageHistory {
+		// 内存中的会话历史按实例区分会话。
+		return NewInMemoryChatHistory()
+	})
+}
+
+func TestJSONLChatHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat.jsonl")
+	testHistory(t, func(sessionID string) ChatMessageHistory {
+		return NewJSONLChatHistory(path, sessionID)
+	})
+}
+
+func TestJSONLChatHistoryReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "chat.jsonl")
+	want := testMessages()
+	if err := NewJSONLChatHistory(path, "alice").AddMessages(ctx, want...); err != nil {
+		t.Fatal(err)
+	}
+	// 新的实例从同一个文件读到相同的消息。
+	assertMessages(t, NewJSONLChatHistory(path, "alice"), want)
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONLChatHistory 把消息以 JSON Lines 格式追加到文件中，每行一条消息。
+// 同一个文件可以保存多个会话，每行都带有会话 ID。
+//
+// 同一进程中指向同一个文件的实例共享一把锁，可以安全地并发使用；
+// 不支持多个进程同时写入同一个文件。
+type JSONLChatHistory struct {
+	mu        *sync.Mutex
+	path      string
+	sessionID string
+}
+
+// fileLocks 按文件路径保存锁，Clear 重写文件时不会覆盖其他实例刚追加的消息。
+var fileLocks sync.Map // map[string]*sync.Mutex
+
+// fileLock 返回 path 对应的锁，路径先转换为绝对路径，使不同写法的同一路径共享一把锁。
+func fileLock(path string) *sync.Mutex {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	mu, _ := fileLocks.LoadOrStore(path, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+var _ ChatMessageHistory = (*JSONLChatHistory)(nil)
+
+type jsonlRecord struct {
+	SessionID string `json:"session_id"`
+	ChatMessage
+}
+
+// NewJSONLChatHistory 创建一个保存在 path 文件中、会话 ID 为 sessionID 的会话历史。
+// 文件不存在时会在第一次写入时创建。
+func NewJSONLChatHistory(path, sessionID string) *JSONLChatHistory {
+	return &JSONLChatHistory{mu: fileLock(path), path: path, sessionID: sessionID}
+}
+
+func (h *JSONLChatHistory) AddMessages(_ context.Context, messages ...ChatMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("memory: failed to open history file: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	for _, m := range withCreatedAt(messages) {
+		if err := enc.Encode(jsonlRecord{SessionID: h.sessionID, ChatMessage: m}); err != nil {
+			f.Close()
+			return fmt.Errorf("memory: failed to write history file: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("memory: failed to write history file: %w", err)
+	}
+	return nil
+}
+
+func (h *JSONLChatHistory) Messages(context.Context) ([]ChatMessage, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records, err := h.readRecords()
+	if err != nil {
+		return nil, err
+	}
+	var messages []ChatMessage
+	for _, r := range records {
+		if r.SessionID == h.sessionID {
+			messages = append(messages, r.ChatMessage)
+		}
+	}
+	return messages, nil
+}
+
+// Clear 删除这个会话的消息，文件中其他会话的消息会被保留。
+func (h *JSONLChatHistory) Clear(ctx context.Context) error {
+	return h.ReplaceMessages(ctx)
+}
+
+// ReplaceMessages 用 messages 替换这个会话的消息，文件中其他会话的消息会被保留。
+func (h *JSONLChatHistory) ReplaceMessages(_ context.Context, messages ...ChatMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records, err := h.readRecords()
+	if err != nil {
+		return err
+	}
+
+	// 先写入临时文件再替换，避免写到一半时丢失消息。
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), filepath.Base(h.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("memory: failed to rewrite history file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	enc := json.NewEncoder(tmp)
+	for _, r := range records {
+		if r.SessionID == h.sessionID {
+			continue
+		}
+		if err := enc.Encode(r); err != nil {
+			tmp.Close()
+			return fmt.Errorf("memory: failed to rewrite history file: %w", err)
+		}
+	}
+	for _, m := range withCreatedAt(messages) {
+		if err := enc.Encode(jsonlRecord{SessionID: h.sessionID, ChatMessage: m}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("memory: failed to rewrite history file: %w", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("memory: failed to rewrite history file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return fmt.Errorf("memory: failed to rewrite history file: %w", err)
+	}
+	return nil
+}
+
+// readRecords 读取文件中所有会话的消息，文件不存在时返回空。
+func (h *JSONLChatHistory) readRecords() ([]jsonlRecord, error) {
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("memory: failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	var records []jsonlRecord
+	dec := json.NewDecoder(f)
+	for {
+		var r jsonlRecord
+		if err := dec.Decode(&r); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("memory: failed to read history file %s: %w", h.path, err)
+		}
+		records = append(records, r)
+	}
+}
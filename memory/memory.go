@@ -0,0 +1,187 @@
+// Package memory 为多轮对话保存历史消息，并在下一次调用模型时把历史交给提示词模板。
+//
+//	mem := memory.NewConversationWindowBuffer(5)
+//	chain := chains.NewConversationChain(llm, mem)
+//	reply, err := chains.Run(ctx, chain, "你好")
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+const (
+	// DefaultMemoryKey 是历史消息在提示词变量中默认使用的键。
+	DefaultMemoryKey = "history"
+	// DefaultHumanPrefix 是把历史渲染为文本时用户消息的前缀。
+	DefaultHumanPrefix = "Human"
+	// DefaultAIPrefix 是把历史渲染为文本时模型回复的前缀。
+	DefaultAIPrefix = "AI"
+	// UsageKey 是 SaveContext 的 outputs 中保存 llms.Usage 的键，
+	// 提供时会记录到这一轮模型回复的消息上。它不会被当作模型回复的内容。
+	UsageKey = "usage"
+)
+
+// ErrAmbiguousKey 表示无法确定应该把哪个输入或输出保存到历史中，需要通过 WithInputKey、WithOutputKey 指定。
+var ErrAmbiguousKey = errors.New("memory: cannot determine which key to save")
+
+// Memory 在链的多次调用之间保存状态。
+type Memory interface {
+	// MemoryVariables 返回 LoadMemoryVariables 会提供的变量名。
+	MemoryVariables() []string
+	// LoadMemoryVariables 返回要合并到链输入中的变量。
+	LoadMemoryVariables(ctx context.Context, inputs map[string]any) (map[string]any, error)
+	// SaveContext 保存一轮对话的输入和输出。
+	SaveContext(ctx context.Context, inputs, outputs map[string]any) error
+	// Clear 清空保存的状态。
+	Clear(ctx context.Context) error
+}
+
+// Option 是用于配置各种对话记忆的函数选项。
+type Option func(*options)
+
+type options struct {
+	memoryKey      string
+	inputKey       string
+	outputKey      string
+	returnMessages bool
+	humanPrefix    string
+	aiPrefix       string
+	chatHistory    ChatMessageHistory
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		memoryKey:      DefaultMemoryKey,
+		returnMessages: true,
+		humanPrefix:    DefaultHumanPrefix,
+		aiPrefix:       DefaultAIPrefix,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.chatHistory == nil {
+		o.chatHistory = NewInMemoryChatHistory()
+	}
+	return o
+}
+
+// WithChatHistory 设置保存消息的会话历史，默认使用 InMemoryChatHistory。
+// 使用 JSONLChatHistory 或 SQLiteChatHistory 可以在进程重启之后继续之前的对话。
+func WithChatHistory(history ChatMessageHistory) Option {
+	return func(o *options) {
+		o.chatHistory = history
+	}
+}
+
+// WithMemoryKey 设置历史消息在提示词变量中的键，默认为 DefaultMemoryKey。
+func WithMemoryKey(key string) Option {
+	return func(o *options) {
+		o.memoryKey = key
+	}
+}
+
+// WithInputKey 设置把哪个输入作为用户消息保存。链只有一个输入时不需要设置。
+func WithInputKey(key string) Option {
+	return func(o *options) {
+		o.inputKey = key
+	}
+}
+
+// WithOutputKey 设置把哪个输出作为模型回复保存。链只有一个输出时不需要设置。
+func WithOutputKey(key string) Option {
+	return func(o *options) {
+		o.outputKey = key
+	}
+}
+
+// WithReturnMessages 设置历史以 []llms.Message 还是以文本的形式提供，默认为 []llms.Message，
+// 配合 prompts.MessagesPlaceholder 使用；设为 false 时适合在普通文本模板中使用。
+func WithReturnMessages(returnMessages bool) Option {
+	return func(o *options) {
+		o.returnMessages = returnMessages
+	}
+}
+
+// WithPrefixes 设置把历史渲染为文本时用户消息和模型回复的前缀。
+func WithPrefixes(human, ai string) Option {
+	return func(o *options) {
+		o.humanPrefix = human
+		o.aiPrefix = ai
+	}
+}
+
+// memoryValue 按配置把消息转换为提示词变量的值。
+func (o options) memoryValue(messages []llms.Message) any {
+	if o.returnMessages {
+		return messages
+	}
+	return o.bufferString(messages)
+}
+
+// bufferString 把消息渲染为每行一条、带前缀的文本。
+func (o options) bufferString(messages []llms.Message) string {
+	lines := make([]string, 0, len(messages))
+	for _, m := range messages {
+		prefix := string(m.Role)
+		switch m.Role {
+		case llms.RoleUser:
+			prefix = o.humanPrefix
+		case llms.RoleAssistant:
+			prefix = o.aiPrefix
+		case llms.RoleSystem:
+			prefix = "System"
+		}
+		lines = append(lines, prefix+": "+m.Content)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// turnMessages 从链的输入和输出中取出这一轮的用户消息和模型回复。
+func (o options) turnMessages(inputs, outputs map[string]any) ([]ChatMessage, error) {
+	input, err := pickValue(inputs, o.inputKey, o.memoryKey, "input")
+	if err != nil {
+		return nil, err
+	}
+	output, err := pickValue(outputs, o.outputKey, o.memoryKey, "output")
+	if err != nil {
+		return nil, err
+	}
+	usage, _ := outputs[UsageKey].(llms.Usage)
+	now := time.Now()
+	return []ChatMessage{
+		{Message: llms.UserMessage(input), CreatedAt: now},
+		{Message: llms.AssistantMessage(output), CreatedAt: now, Usage: usage},
+	}, nil
+}
+
+// pickValue 返回 key 对应的值；key 为空时要求 values 中除了 memoryKey 和 UsageKey 之外只有一个键。
+func pickValue(values map[string]any, key, memoryKey, kind string) (string, error) {
+	if key == "" {
+		for k := range values {
+			if k == memoryKey || k == UsageKey {
+				continue
+			}
+			if key != "" {
+				return "", fmt.Errorf("%w: multiple %s keys", ErrAmbiguousKey, kind)
+			}
+			key = k
+		}
+		if key == "" {
+			return "", fmt.Errorf("%w: no %s keys", ErrAmbiguousKey, kind)
+		}
+	}
+	v, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("memory: %s key %q not found", kind, key)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(v), nil
+}
@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// DefaultSQLiteTable 是 SQLiteChatHistory 默认使用的表名。
+const DefaultSQLiteTable = "chat_messages"
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLiteChatHistory 把消息保存在 SQLite 表中，按会话 ID 区分不同的会话。
+//
+// 为了不给本模块引入 cgo 或者额外的依赖，db 由调用方打开，驱动也由调用方选择，
+// 例如 modernc.org/sqlite 或 github.com/mattn/go-sqlite3：
+//
+//	db, err := sql.Open("sqlite", "chat.db")
+//	history, err := memory.NewSQLiteChatHistory(ctx, db, "user-42")
+type SQLiteChatHistory struct {
+	db        *sql.DB
+	sessionID string
+	table     string
+}
+
+var _ ChatMessageHistory = (*SQLiteChatHistory)(nil)
+
+// SQLiteOption 是用于配置 SQLiteChatHistory 的函数选项。
+type SQLiteOption func(*SQLiteChatHistory)
+
+// WithTableName 设置保存消息的表名，默认为 DefaultSQLiteTable。
+func WithTableName(table string) SQLiteOption {
+	return func(h *SQLiteChatHistory) {
+		h.table = table
+	}
+}
+
+// NewSQLiteChatHistory 创建一个会话 ID 为 sessionID 的会话历史，表不存在时会自动创建。
+func NewSQLiteChatHistory(ctx context.Context, db *sql.DB, sessionID string, opts ...SQLiteOption) (*SQLiteChatHistory, error) {
+	h := &SQLiteChatHistory{db: db, sessionID: sessionID, table: DefaultSQLiteTable}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if !tableNamePattern.MatchString(h.table) {
+		return nil, fmt.Errorf("memory: invalid table name %q", h.table)
+	}
+
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS ` + h.table + ` (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			content TEXT NOT NULL,
+			tool_calls TEXT NOT NULL DEFAULT '',
+			tool_call_id TEXT NOT NULL DEFAULT '',
+			name TEXT NOT NULL DEFAULT '',
+			prompt_tokens INTEGER NOT NULL DEFAULT 0,
+			completion_tokens INTEGER NOT NULL DEFAULT 0,
+			total_tokens INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS ` + h.table + `_session_idx ON ` + h.table + ` (session_id, id)`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("memory: failed to create table %s: %w", h.table, err)
+		}
+	}
+	return h, nil
+}
+
+func (h *SQLiteChatHistory) AddMessages(ctx context.Context, messages ...ChatMessage) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("memory: failed to save messages: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := h.insertMessages(ctx, tx, messages); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("memory: failed to save messages: %w", err)
+	}
+	return nil
+}
+
+// insertMessages 在事务 tx 中插入 messages。
+func (h *SQLiteChatHistory) insertMessages(ctx context.Context, tx *sql.Tx, messages []ChatMessage) error {
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO `+h.table+` (session_id, role, content, tool_calls, tool_call_id, name,
+		prompt_tokens, completion_tokens, total_tokens, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("memory: failed to save messages: %w", err)
+	}
+	defer stmt.Close()
+
+	// 零值 time.Time 的 UnixNano 没有定义，保存前先补上当前时间。
+	for _, m := range withCreatedAt(messages) {
+		var toolCalls string
+		if len(m.ToolCalls) > 0 {
+			b, err := json.Marshal(m.ToolCalls)
+			if err != nil {
+				return fmt.Errorf("memory: failed to marshal tool calls: %w", err)
+			}
+			toolCalls = string(b)
+		}
+		if _, err := stmt.ExecContext(ctx, h.sessionID, string(m.Role), m.Content, toolCalls, m.ToolCallID, m.Name,
+			m.Usage.PromptTokens, m.Usage.CompletionTokens, m.Usage.TotalTokens, m.CreatedAt.UnixNano()); err != nil {
+			return fmt.Errorf("memory: failed to save messages: %w", err)
+		}
+	}
+	return nil
+}
+
+func (h *SQLiteChatHistory) Messages(ctx context.Context) ([]ChatMessage, error) {
+	rows, err := h.db.QueryContext(ctx, `SELECT role, content, tool_calls, tool_call_id, name,
+		prompt_tokens, completion_tokens, total_tokens, created_at FROM `+h.table+` WHERE session_id = ? ORDER BY id`, h.sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("memory: failed to load messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []ChatMessage
+	for rows.Next() {
+		var (
+			m         ChatMessage
+			role      string
+			toolCalls string
+			createdAt int64
+		)
+		if err := rows.Scan(&role, &m.Content, &toolCalls, &m.ToolCallID, &m.Name,
+			&m.Usage.PromptTokens, &m.Usage.CompletionTokens, &m.Usage.TotalTokens, &createdAt); err != nil {
+			return nil, fmt.Errorf("memory: failed to load messages: %w", err)
+		}
+		m.Role = llms.Role(role)
+		m.CreatedAt = time.Unix(0, createdAt)
+		if toolCalls != "" {
+			if err := json.Unmarshal([]byte(toolCalls), &m.ToolCalls); err != nil {
+				return nil, fmt.Errorf("memory: failed to unmarshal tool calls: %w", err)
+			}
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("memory: failed to load messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (h *SQLiteChatHistory) Clear(ctx context.Context) error {
+	if _, err := h.db.ExecContext(ctx, `DELETE FROM `+h.table+` WHERE session_id = ?`, h.sessionID); err != nil {
+		return fmt.Errorf("memory: failed to clear messages: %w", err)
+	}
+	return nil
+}
+
+// ReplaceMessages 在同一个事务中删除会话的全部消息并插入 messages。
+func (h *SQLiteChatHistory) ReplaceMessages(ctx context.Context, messages ...ChatMessage) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("memory: failed to replace messages: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+h.table+` WHERE session_id = ?`, h.sessionID); err != nil {
+		return fmt.Errorf("memory: failed to replace messages: %w", err)
+	}
+	if err := h.insertMessages(ctx, tx, messages); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("memory: failed to replace messages: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// fakeSQL 是只理解 SQLiteChatHistory 所用语句的 database/sql 驱动，
+// 让测试不依赖 cgo 或第三方 SQLite 驱动。事务中的写入在提交前对其他连接不可见。
+type fakeSQL struct {
+	mu sync.Mutex
+	// rows 是已提交的行，每行依次为 INSERT 的参数。
+	rows [][]driver.Value
+	// failInsert 为 true 时所有 INSERT 都会失败，用于检查事务回滚。
+	failInsert bool
+}
+
+var fakeSQLStores sync.Map // map[string]*fakeSQL
+
+func init() {
+	sql.Register("memory_fake_sql", fakeSQLDriver{})
+}
+
+type fakeSQLDriver struct{}
+
+func (fakeSQLDriver) Open(name string) (driver.Conn, error) {
+	store, _ := fakeSQLStores.LoadOrStore(name, &fakeSQL{})
+	return &fakeConn{store: store.(*fakeSQL)}, nil
+}
+
+type fakeConn struct {
+	store *fakeSQL
+	// tx 不为 nil 时是事务中的行，提交时替换已提交的行。
+	tx *[][]driver.Value
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.store.mu.Lock()
+	rows := slices.Clone(c.store.rows)
+	c.store.mu.Unlock()
+	c.tx = &rows
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.store.mu.Lock()
+	c.store.rows = *c.tx
+	c.store.mu.Unlock()
+	c.tx = nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.tx = nil
+	return nil
+}
+
+// update 在事务或已提交的行上执行 fn。
+func (c *fakeConn) update(fn func(rows *[][]driver.Value) error) error {
+	if c.tx != nil {
+		return fn(c.tx)
+	}
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	return fn(&c.store.rows)
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	query := strings.TrimSpace(s.query)
+	switch {
+	case strings.HasPrefix(query, "CREATE"):
+	case strings.HasPrefix(query, "INSERT"):
+		if s.conn.store.failInsert {
+			return nil, errors.New("insert failed")
+		}
+		return driver.RowsAffected(1), s.conn.update(func(rows *[][]driver.Value) error {
+			*rows = append(*rows, slices.Clone(args))
+			return nil
+		})
+	case strings.HasPrefix(query, "DELETE"):
+		return driver.RowsAffected(0), s.conn.update(func(rows *[][]driver.Value) error {
+			*rows = slices.DeleteFunc(*rows, func(r []driver.Value) bool { return r[0] == args[0] })
+			return nil
+		})
+	default:
+		return nil, errors.New("unsupported statement: " + query)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	var out [][]driver.Value
+	err := s.conn.update(func(rows *[][]driver.Value) error {
+		for _, r := range *rows {
+			if r[0] == args[0] {
+				out = append(out, r[1:])
+			}
+		}
+		return nil
+	})
+	return &fakeRows{rows: out}, err
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"role", "content", "tool_calls", "tool_call_id", "name",
+		"prompt_tokens", "completion_tokens", "total_tokens", "created_at"}
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func openFakeSQL(t *testing.T) (*sql.DB, *fakeSQL) {
+	t.Helper()
+	db, err := sql.Open("memory_fake_sql", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, _ := fakeSQLStores.LoadOrStore(t.Name(), &fakeSQL{})
+	return db, store.(*fakeSQL)
+}
+
+func TestSQLiteChatHistory(t *testing.T) {
+	db, _ := openFakeSQL(t)
+	testHistory(t, func(sessionID string) ChatMessageHistory {
+		h, err := NewSQLiteChatHistory(context.Background(), db, sessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	})
+}
+
+func TestSQLiteChatHistoryReplaceIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	db, store := openFakeSQL(t)
+	h, err := NewSQLiteChatHistory(ctx, db, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testMessages()
+	if err := h.AddMessages(ctx, want...); err != nil {
+		t.Fatal(err)
+	}
+
+	// 插入失败时删除也会回滚，原有消息保持不变。
+	store.failInsert = true
+	if err := h.ReplaceMessages(ctx, ChatMessage{Message: llms.SystemMessage("summary")}); err == nil {
+		t.Fatal("ReplaceMessages succeeded, want error")
+	}
+	store.failInsert = false
+	assertMessages(t, h, want)
+}
+
+func TestSQLiteChatHistoryInvalidTable(t *testing.T) {
+	db, _ := openFakeSQL(t)
+	if _, err := NewSQLiteChatHistory(context.Background(), db, "alice", WithTableName("messages; DROP TABLE x")); err == nil {
+		t.Error("invalid table name was accepted")
+	}
+}
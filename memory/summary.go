@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+const summaryPrompt = `Progressively summarize the lines of conversation provided, adding onto the previous summary and returning a new summary. Write the summary in the same language as the conversation.
+
+Current summary:
+%s
+
+New lines of conversation:
+%s
+
+New summary:`
+
+// ErrSummarizeFailed 表示 ConversationSummaryBuffer 调用模型更新摘要失败。
+var ErrSummarizeFailed = errors.New("memory: failed to summarize conversation")
+
+// summaryMessageName 标记会话历史中保存摘要的系统消息。
+const summaryMessageName = "conversation_summary"
+
+// ConversationSummaryBuffer 保留不超过 token 预算的最近消息，更早的消息交给模型压缩成摘要。
+// 提供给模型的历史是一条包含摘要的系统消息加上最近的消息。
+//
+// 摘要作为第一条系统消息保存在同一个会话历史中，使用持久化的会话历史时，
+// 进程重启后摘要和最近的消息都会恢复；被压缩的消息会从会话历史中删除。
+type ConversationSummaryBuffer struct {
+	*ConversationBuffer
+	mu        sync.Mutex
+	llm       llms.LLM
+	maxTokens int
+	summary   string
+}
+
+var _ Memory = (*ConversationSummaryBuffer)(nil)
+
+// NewConversationSummaryBuffer 创建一个使用 llm 压缩历史的记忆。
+// 最近消息的估算 token 数超过 maxTokens 时，最早的消息会被合并进摘要。
+func NewConversationSummaryBuffer(llm llms.LLM, maxTokens int, opts ...Option) *ConversationSummaryBuffer {
+	return &ConversationSummaryBuffer{
+		ConversationBuffer: NewConversationBuffer(opts...),
+		llm:                llm,
+		maxTokens:          maxTokens,
+	}
+}
+
+func (s *ConversationSummaryBuffer) LoadMemoryVariables(ctx context.Context, _ map[string]any) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages, err := s.opts.chatHistory.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summary, rest := splitSummary(messages)
+	s.summary = summary
+	out := toLLMMessages(rest)
+	if summary != "" {
+		out = append([]llms.Message{llms.SystemMessage("Summary of the earlier conversation:\n" + summary)}, out...)
+	}
+	return map[string]any{s.opts.memoryKey: s.opts.memoryValue(out)}, nil
+}
+
+// SaveContext 保存这一轮对话，超出 token 预算时调用模型更新摘要。
+// 更新摘要失败时返回包装了 ErrSummarizeFailed 的错误，此时这一轮对话已经保存，
+// 较早的消息也会保留，留到下一次再压缩。
+func (s *ConversationSummaryBuffer) SaveContext(ctx context.Context, inputs, outputs map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ConversationBuffer.SaveContext(ctx, inputs, outputs); err != nil {
+		return err
+	}
+	history := s.opts.chatHistory
+	messages, err := history.Messages(ctx)
+	if err != nil {
+		return err
+	}
+	summary, rest := splitSummary(messages)
+	s.summary = summary
+	kept := trimToTokens(toLLMMessages(rest), s.maxTokens)
+	pruned := len(rest) - len(kept)
+	if pruned == 0 {
+		return nil
+	}
+
+	summary, err = s.summarize(ctx, summary, toLLMMessages(rest[:pruned]))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSummarizeFailed, err)
+	}
+	s.summary = summary
+
+	// 原子地替换为摘要和保留的消息，写入失败时原有的会话历史保持不变。
+	saved := make([]ChatMessage, 0, len(rest)-pruned+1)
+	saved = append(saved, ChatMessage{
+		Message:   llms.Message{Role: llms.RoleSystem, Name: summaryMessageName, Content: summary},
+		CreatedAt: time.Now(),
+	})
+	saved = append(saved, rest[pruned:]...)
+	return history.ReplaceMessages(ctx, saved...)
+}
+
+func (s *ConversationSummaryBuffer) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summary = ""
+	return s.ConversationBuffer.Clear(ctx)
+}
+
+// Messages 返回会话历史中的最近消息，不包含保存摘要的系统消息。
+func (s *ConversationSummaryBuffer) Messages(ctx context.Context) ([]llms.Message, error) {
+	messages, err := s.opts.chatHistory.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, rest := splitSummary(messages)
+	return toLLMMessages(rest), nil
+}
+
+// Summary 返回最近一次加载或保存时的摘要，还没有压缩过历史时为空。
+func (s *ConversationSummaryBuffer) Summary() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.summary
+}
+
+// splitSummary 把会话历史拆分为开头保存的摘要和其余的消息。
+func splitSummary(messages []ChatMessage) (string, []ChatMessage) {
+	if len(messages) > 0 && messages[0].Role == llms.RoleSystem && messages[0].Name == summaryMessageName {
+		return messages[0].Content, messages[1:]
+	}
+	return "", messages
+}
+
+// summarize 把 messages 合并进 summary，返回新的摘要。
+func (s *ConversationSummaryBuffer) summarize(ctx context.Context, summary string, messages []llms.Message) (string, error) {
+	prompt := fmt.Sprintf(summaryPrompt, summary, s.opts.bufferString(messages))
+	switch m := s.llm.(type) {
+	case llms.ChatModel:
+		return llms.GenerateFromSinglePrompt(ctx, m, prompt)
+	case llms.ContextLLM:
+		return m.CallContext(ctx, prompt)
+	default:
+		return s.llm.Call(prompt)
+	}
+}
@@ -0,0 +1,90 @@
+// Package outputparser 把模型输出的文本解析为程序可以直接使用的值。
+package outputparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zideajang/langChaingo/structured"
+)
+
+// ErrParse 表示模型输出无法按预期格式解析。
+var ErrParse = errors.New("outputparser: failed to parse output")
+
+// OutputParser 解析模型输出。
+type OutputParser interface {
+	// Parse 把模型输出的文本解析为值。
+	Parse(text string) (any, error)
+	// GetFormatInstructions 返回告诉模型应该按什么格式输出的提示词，不需要时为空。
+	GetFormatInstructions() string
+}
+
+// Simple 去掉输出首尾的空白后原样返回字符串。
+type Simple struct{}
+
+var _ OutputParser = Simple{}
+
+// NewSimple 创建一个 Simple 解析器。
+func NewSimple() Simple { return Simple{} }
+
+func (Simple) Parse(text string) (any, error) {
+	return strings.TrimSpace(text), nil
+}
+
+func (Simple) GetFormatInstructions() string { return "" }
+
+// CommaSeparatedList 把逗号分隔的输出解析为 []string，同时支持中文逗号。
+type CommaSeparatedList struct{}
+
+var _ OutputParser = CommaSeparatedList{}
+
+// NewCommaSeparatedList 创建一个 CommaSeparatedList 解析器。
+func NewCommaSeparatedList() CommaSeparatedList { return CommaSeparatedList{} }
+
+func (CommaSeparatedList) Parse(text string) (any, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '，'
+	})
+	items := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			items = append(items, f)
+		}
+	}
+	return items, nil
+}
+
+func (CommaSeparatedList) GetFormatInstructions() string {
+	return "Your response should be a list of comma separated values, eg: `foo, bar, baz`"
+}
+
+// Structured 把 JSON 输出按 T 的 JSON Schema 校验后解码为 T。
+type Structured[T any] struct {
+	schema       structured.Schema
+	instructions string
+}
+
+// NewStructured 为类型 T 创建一个 Structured 解析器。
+func NewStructured[T any]() (Structured[T], error) {
+	schema, err := structured.SchemaOf[T]()
+	if err != nil {
+		return Structured[T]{}, err
+	}
+	instructions, err := structured.FormatInstructions(schema)
+	if err != nil {
+		return Structured[T]{}, err
+	}
+	return Structured[T]{schema: schema, instructions: instructions}, nil
+}
+
+// Parse 返回的值的类型是 T。
+func (p Structured[T]) Parse(text string) (any, error) {
+	v, err := structured.Decode[T](text, p.schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+	return v, nil
+}
+
+func (p Structured[T]) GetFormatInstructions() string { return p.instructions }
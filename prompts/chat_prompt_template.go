@@ -0,0 +1,191 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// MessageFormatter 是可以渲染出一条或多条消息的模板。
+type MessageFormatter interface {
+	FormatMessages(values map[string]any) ([]llms.Message, error)
+	GetInputVariables() []string
+}
+
+// ChatPromptValue 是渲染后的消息列表，作为纯文本使用时每条消息占一行并带上角色前缀。
+type ChatPromptValue []llms.Message
+
+func (v ChatPromptValue) String() string {
+	lines := make([]string, 0, len(v))
+	for _, m := range v {
+		lines = append(lines, fmt.Sprintf("%s: %s", m.Role, m.Content))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (v ChatPromptValue) Messages() []llms.Message {
+	return []llms.Message(v)
+}
+
+// MessagePromptTemplate 渲染出一条指定角色的消息。
+type MessagePromptTemplate struct {
+	Role   llms.Role
+	Prompt PromptTemplate
+}
+
+var _ MessageFormatter = MessagePromptTemplate{}
+
+// NewMessagePromptTemplate 使用任意语法的模板创建一条指定角色的消息模板。
+func NewMessagePromptTemplate(role llms.Role, prompt PromptTemplate) MessagePromptTemplate {
+	return MessagePromptTemplate{Role: role, Prompt: prompt}
+}
+
+// NewSystemMessagePromptTemplate 创建一条系统消息模板，使用 Go text/template 语法。
+func NewSystemMessagePromptTemplate(template string, inputVariables []string) MessagePromptTemplate {
+	return NewMessagePromptTemplate(llms.RoleSystem, NewPromptTemplate(template, inputVariables))
+}
+
+// NewUserMessagePromptTemplate 创建一条用户消息模板，使用 Go text/template 语法。
+func NewUserMessagePromptTemplate(template string, inputVariables []string) MessagePromptTemplate {
+	return NewMessagePromptTemplate(llms.RoleUser, NewPromptTemplate(template, inputVariables))
+}
+
+// NewAssistantMessagePromptTemplate 创建一条模型回复消息模板，使用 Go text/template 语法。
+func NewAssistantMessagePromptTemplate(template string, inputVariables []string) MessagePromptTemplate {
+	return NewMessagePromptTemplate(llms.RoleAssistant, NewPromptTemplate(template, inputVariables))
+}
+
+func (m MessagePromptTemplate) FormatMessages(values map[string]any) ([]llms.Message, error) {
+	content, err := m.Prompt.Format(values)
+	if err != nil {
+		return nil, err
+	}
+	return []llms.Message{{Role: m.Role, Content: content}}, nil
+}
+
+func (m MessagePromptTemplate) GetInputVariables() []string {
+	return m.Prompt.GetInputVariables()
+}
+
+// MessagesPlaceholder 在模板中插入一组由调用方提供的消息，例如对话历史。
+// 对应变量的值必须是 []llms.Message。
+type MessagesPlaceholder struct {
+	VariableName string
+}
+
+var _ MessageFormatter = MessagesPlaceholder{}
+
+func (p MessagesPlaceholder) FormatMessages(values map[string]any) ([]llms.Message, error) {
+	v, ok := values[p.VariableName]
+	if !ok {
+		return nil, &ValidationError{Missing: []string{p.VariableName}}
+	}
+	messages, ok := v.([]llms.Message)
+	if !ok {
+		return nil, fmt.Errorf("prompts: variable %q must be []llms.Message, got %T", p.VariableName, v)
+	}
+	return messages, nil
+}
+
+func (p MessagesPlaceholder) GetInputVariables() []string {
+	return []string{p.VariableName}
+}
+
+// ChatPromptTemplate 由多条消息模板组成，渲染后得到带角色的消息列表，
+// 可以直接交给 llms.ChatModel 的 GenerateContent。
+type ChatPromptTemplate struct {
+	Messages []MessageFormatter
+	// PartialVariables 是预先填好的变量，会传给所有消息模板。
+	PartialVariables map[string]any
+}
+
+var _ FormatPrompter = ChatPromptTemplate{}
+
+// NewChatPromptTemplate 创建一个聊天提示词模板。
+func NewChatPromptTemplate(messages ...MessageFormatter) ChatPromptTemplate {
+	return ChatPromptTemplate{Messages: messages}
+}
+
+// WithPartialVariables 返回一个填好了部分变量的新模板。
+func (c ChatPromptTemplate) WithPartialVariables(values map[string]any) ChatPromptTemplate {
+	partials := maps.Clone(c.PartialVariables)
+	if partials == nil {
+		partials = map[string]any{}
+	}
+	maps.Copy(partials, values)
+	c.PartialVariables = partials
+	return c
+}
+
+// GetInputVariables 返回所有消息模板需要的变量（不包含已经预填的变量），已排序并去重。
+func (c ChatPromptTemplate) GetInputVariables() []string {
+	seen := map[string]bool{}
+	var vars []string
+	for _, m := range c.Messages {
+		for _, v := range m.GetInputVariables() {
+			if _, partial := c.PartialVariables[v]; partial || seen[v] {
+				continue
+			}
+			seen[v] = true
+			vars = append(vars, v)
+		}
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// Validate 逐条校验消息模板，返回合并后的 *ValidationError。
+func (c ChatPromptTemplate) Validate() error {
+	combined := &ValidationError{}
+	for _, m := range c.Messages {
+		mt, ok := m.(MessagePromptTemplate)
+		if !ok {
+			continue
+		}
+		err := mt.Prompt.Validate()
+		if err == nil {
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			return err
+		}
+		combined.Missing = append(combined.Missing, verr.Missing...)
+		combined.Unused = append(combined.Unused, verr.Unused...)
+	}
+	if len(combined.Missing) == 0 && len(combined.Unused) == 0 {
+		return nil
+	}
+	return combined
+}
+
+// FormatMessages 渲染所有消息模板。渲染之前先检查所有变量都已提供，
+// 缺少变量时返回列出全部缺少变量的 *ValidationError。
+func (c ChatPromptTemplate) FormatMessages(values map[string]any) ([]llms.Message, error) {
+	merged, err := mergeValues(c.GetInputVariables(), c.PartialVariables, values)
+	if err != nil {
+		return nil, err
+	}
+	var messages []llms.Message
+	for _, m := range c.Messages {
+		formatted, err := m.FormatMessages(merged)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, formatted...)
+	}
+	return messages, nil
+}
+
+// FormatPrompt 渲染所有消息模板并返回 ChatPromptValue。
+func (c ChatPromptTemplate) FormatPrompt(values map[string]any) (PromptValue, error) {
+	messages, err := c.FormatMessages(values)
+	if err != nil {
+		return nil, err
+	}
+	return ChatPromptValue(messages), nil
+}
@@ -0,0 +1,195 @@
+// Package prompts 提供提示词模板，在调用模型之前完成变量校验和渲染。
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// ErrInvalidTemplate 表示模板变量与声明不一致或者渲染时缺少变量。
+var ErrInvalidTemplate = errors.New("prompts: invalid template variables")
+
+// ValidationError 列出模板中缺少和多余的变量，它包装了 ErrInvalidTemplate。
+type ValidationError struct {
+	Missing []string // 模板中引用了但没有声明（或渲染时没有提供值）的变量
+	Unused  []string // 声明了但模板中没有引用的变量
+}
+
+func (e *ValidationError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing variables "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unused) > 0 {
+		parts = append(parts, "unused variables "+strings.Join(e.Unused, ", "))
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidTemplate, strings.Join(parts, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidTemplate
+}
+
+// PromptValue 是渲染后的提示词，既可以作为纯文本发送给 llms.LLM，
+// 也可以作为消息列表发送给 llms.ChatModel。
+type PromptValue interface {
+	String() string
+	Messages() []llms.Message
+}
+
+// FormatPrompter 是可以根据变量渲染出 PromptValue 的模板。
+type FormatPrompter interface {
+	FormatPrompt(values map[string]any) (PromptValue, error)
+	GetInputVariables() []string
+}
+
+// StringPromptValue 是纯文本的提示词，作为消息使用时是一条用户消息。
+type StringPromptValue string
+
+func (v StringPromptValue) String() string { return string(v) }
+
+func (v StringPromptValue) Messages() []llms.Message {
+	return []llms.Message{llms.UserMessage(string(v))}
+}
+
+// PromptTemplate 是单段文本的提示词模板。
+type PromptTemplate struct {
+	// Template 是模板文本。
+	Template string
+	// InputVariables 是调用 Format 时需要提供的变量。
+	InputVariables []string
+	// TemplateFormat 是模板语法，为空时使用 TemplateFormatGoTemplate。
+	TemplateFormat TemplateFormat
+	// PartialVariables 是预先填好的变量，调用 Format 时不需要再提供。
+	PartialVariables map[string]any
+}
+
+var _ FormatPrompter = PromptTemplate{}
+
+// NewPromptTemplate 创建一个使用 Go text/template 语法的模板。
+func NewPromptTemplate(template string, inputVariables []string) PromptTemplate {
+	return PromptTemplate{
+		Template:       template,
+		InputVariables: inputVariables,
+		TemplateFormat: TemplateFormatGoTemplate,
+	}
+}
+
+// NewFStringPromptTemplate 创建一个使用 {var} 语法的模板。
+func NewFStringPromptTemplate(template string, inputVariables []string) PromptTemplate {
+	return PromptTemplate{
+		Template:       template,
+		InputVariables: inputVariables,
+		TemplateFormat: TemplateFormatFString,
+	}
+}
+
+// WithPartialVariables 返回一个填好了部分变量的新模板，这些变量会从 InputVariables 中移除。
+func (p PromptTemplate) WithPartialVariables(values map[string]any) PromptTemplate {
+	partials := maps.Clone(p.PartialVariables)
+	if partials == nil {
+		partials = map[string]any{}
+	}
+	maps.Copy(partials, values)
+
+	inputs := make([]string, 0, len(p.InputVariables))
+	for _, v := range p.InputVariables {
+		if _, ok := partials[v]; !ok {
+			inputs = append(inputs, v)
+		}
+	}
+	p.InputVariables = inputs
+	p.PartialVariables = partials
+	return p
+}
+
+// Variables 返回模板文本中实际引用的变量。
+func (p PromptTemplate) Variables() ([]string, error) {
+	return templateVariables(p.Template, p.TemplateFormat)
+}
+
+// Validate 检查模板能否解析，以及模板引用的变量与 InputVariables、PartialVariables 是否一致。
+// 不一致时返回 *ValidationError，列出缺少和多余的变量。
+func (p PromptTemplate) Validate() error {
+	used, err := p.Variables()
+	if err != nil {
+		return err
+	}
+	declared := map[string]bool{}
+	for _, v := range p.InputVariables {
+		declared[v] = true
+	}
+	for v := range p.PartialVariables {
+		declared[v] = true
+	}
+	return compareVariables(used, declared)
+}
+
+// GetInputVariables 返回调用 Format 时需要提供的变量。
+func (p PromptTemplate) GetInputVariables() []string {
+	return p.InputVariables
+}
+
+// Format 使用 values 和 PartialVariables 渲染模板。
+// 渲染之前会检查所有 InputVariables 都已提供，缺少时返回 *ValidationError。
+func (p PromptTemplate) Format(values map[string]any) (string, error) {
+	merged, err := mergeValues(p.InputVariables, p.PartialVariables, values)
+	if err != nil {
+		return "", err
+	}
+	return renderTemplate(p.Template, p.TemplateFormat, merged)
+}
+
+// FormatPrompt 渲染模板并返回 StringPromptValue。
+func (p PromptTemplate) FormatPrompt(values map[string]any) (PromptValue, error) {
+	s, err := p.Format(values)
+	if err != nil {
+		return nil, err
+	}
+	return StringPromptValue(s), nil
+}
+
+// mergeValues 合并预填变量和调用时提供的变量，并检查 required 中的变量是否都有值。
+func mergeValues(required []string, partials, values map[string]any) (map[string]any, error) {
+	merged := make(map[string]any, len(partials)+len(values))
+	maps.Copy(merged, partials)
+	maps.Copy(merged, values)
+
+	var missing []string
+	for _, v := range required {
+		if _, ok := merged[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, &ValidationError{Missing: missing}
+	}
+	return merged, nil
+}
+
+// compareVariables 比较模板引用的变量和声明的变量。
+func compareVariables(used []string, declared map[string]bool) error {
+	var missing, unused []string
+	for _, v := range used {
+		if !declared[v] {
+			missing = append(missing, v)
+		}
+	}
+	for v := range declared {
+		if !slices.Contains(used, v) {
+			unused = append(unused, v)
+		}
+	}
+	if len(missing) == 0 && len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+	return &ValidationError{Missing: missing, Unused: unused}
+}
@@ -0,0 +1,180 @@
+package prompts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateFormat 表示模板使用的语法。
+type TemplateFormat string
+
+const (
+	// TemplateFormatGoTemplate 使用 Go 的 text/template 语法，例如 {{.question}}。
+	TemplateFormatGoTemplate TemplateFormat = "go-template"
+	// TemplateFormatFString 使用 Python f-string 风格的语法，例如 {question}，{{ 和 }} 表示字面的花括号。
+	TemplateFormatFString TemplateFormat = "f-string"
+)
+
+// renderTemplate 使用指定语法渲染模板。
+func renderTemplate(tmpl string, format TemplateFormat, values map[string]any) (string, error) {
+	switch format {
+	case TemplateFormatGoTemplate, "":
+		t, err := template.New("prompt").Option("missingkey=error").Parse(tmpl)
+		if err != nil {
+			return "", fmt.Errorf("prompts: parse template: %w", err)
+		}
+		var b strings.Builder
+		if err := t.Execute(&b, values); err != nil {
+			return "", fmt.Errorf("prompts: execute template: %w", err)
+		}
+		return b.String(), nil
+	case TemplateFormatFString:
+		return renderFString(tmpl, values)
+	}
+	return "", fmt.Errorf("prompts: unknown template format %q", format)
+}
+
+// templateVariables 返回模板中引用的全部变量名，已排序并去重。
+func templateVariables(tmpl string, format TemplateFormat) ([]string, error) {
+	seen := map[string]bool{}
+	switch format {
+	case TemplateFormatGoTemplate, "":
+		t, err := template.New("prompt").Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("prompts: parse template: %w", err)
+		}
+		if t.Tree != nil {
+			walkGoTemplate(t.Tree.Root, true, seen)
+		}
+	case TemplateFormatFString:
+		if err := walkFString(tmpl, func(name string) { seen[name] = true }); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("prompts: unknown template format %q", format)
+	}
+
+	vars := make([]string, 0, len(seen))
+	for name := range seen {
+		vars = append(vars, name)
+	}
+	sort.Strings(vars)
+	return vars, nil
+}
+
+// walkGoTemplate 遍历 text/template 的语法树收集顶层变量。
+// rootDot 表示当前的 "." 是否仍然指向传入的 values：在 range、with 的主体中
+// "." 被重新绑定，此时 .x 不再是模板变量，只有 $.x 才是。
+func walkGoTemplate(node parse.Node, rootDot bool, seen map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkGoTemplate(child, rootDot, seen)
+		}
+	case *parse.ActionNode:
+		walkGoTemplate(n.Pipe, rootDot, seen)
+	case *parse.IfNode:
+		walkGoTemplate(n.Pipe, rootDot, seen)
+		walkGoTemplate(n.List, rootDot, seen)
+		walkGoTemplate(n.ElseList, rootDot, seen)
+	case *parse.RangeNode:
+		walkGoTemplate(n.Pipe, rootDot, seen)
+		walkGoTemplate(n.List, false, seen)
+		walkGoTemplate(n.ElseList, rootDot, seen)
+	case *parse.WithNode:
+		walkGoTemplate(n.Pipe, rootDot, seen)
+		walkGoTemplate(n.List, false, seen)
+		walkGoTemplate(n.ElseList, rootDot, seen)
+	case *parse.TemplateNode:
+		walkGoTemplate(n.Pipe, rootDot, seen)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkGoTemplate(cmd, rootDot, seen)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkGoTemplate(arg, rootDot, seen)
+		}
+	case *parse.FieldNode:
+		if rootDot && len(n.Ident) > 0 {
+			seen[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			seen[n.Ident[1]] = true
+		}
+	case *parse.ChainNode:
+		walkGoTemplate(n.Node, rootDot, seen)
+	}
+}
+
+// renderFString 渲染 f-string 风格的模板。
+func renderFString(tmpl string, values map[string]any) (string, error) {
+	var b strings.Builder
+	var missing []string
+	err := scanFString(tmpl, func(text string) {
+		b.WriteString(text)
+	}, func(name string) {
+		v, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+			return
+		}
+		fmt.Fprint(&b, v)
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(missing) > 0 {
+		return "", &ValidationError{Missing: missing}
+	}
+	return b.String(), nil
+}
+
+func walkFString(tmpl string, onVar func(name string)) error {
+	return scanFString(tmpl, func(string) {}, onVar)
+}
+
+// scanFString 按顺序扫描 f-string 模板，普通文本交给 onText，{name} 交给 onVar。
+func scanFString(tmpl string, onText func(text string), onVar func(name string)) error {
+	for i := 0; i < len(tmpl); {
+		switch c := tmpl[i]; {
+		case c == '{' && i+1 < len(tmpl) && tmpl[i+1] == '{':
+			onText("{")
+			i += 2
+		case c == '}' && i+1 < len(tmpl) && tmpl[i+1] == '}':
+			onText("}")
+			i += 2
+		case c == '{':
+			end := strings.IndexByte(tmpl[i+1:], '}')
+			if end < 0 {
+				return fmt.Errorf("prompts: unclosed '{' at offset %d", i)
+			}
+			name := strings.TrimSpace(tmpl[i+1 : i+1+end])
+			if name == "" || strings.ContainsAny(name, "{ ") {
+				return fmt.Errorf("prompts: invalid variable %q at offset %d", tmpl[i:i+2+end], i)
+			}
+			onVar(name)
+			i += end + 2
+		case c == '}':
+			return fmt.Errorf("prompts: single '}' at offset %d", i)
+		default:
+			next := strings.IndexAny(tmpl[i:], "{}")
+			if next < 0 {
+				next = len(tmpl) - i
+			}
+			onText(tmpl[i : i+next])
+			i += next
+		}
+	}
+	return nil
+}
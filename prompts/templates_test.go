@@ -0,0 +1,117 @@
+package prompts
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFStringTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   map[string]any
+		want     string
+		wantVars []string
+		wantErr  string
+	}{
+		{
+			name:     "variables",
+			template: "用{style}的风格介绍{ topic }",
+			values:   map[string]any{"style": "幽默", "topic": "Go"},
+			want:     "用幽默的风格介绍Go",
+			wantVars: []string{"style", "topic"},
+		},
+		{
+			name:     "escaped braces",
+			template: `输出 {{"city": "{city}"}}`,
+			values:   map[string]any{"city": "上海"},
+			want:     `输出 {"city": "上海"}`,
+			wantVars: []string{"city"},
+		},
+		{
+			name:     "repeated variable",
+			template: "{a}-{a}",
+			values:   map[string]any{"a": 1},
+			want:     "1-1",
+			wantVars: []string{"a"},
+		},
+		{
+			name:     "no variables",
+			template: "plain text",
+			want:     "plain text",
+			wantVars: []string{},
+		},
+		{name: "unclosed brace", template: "hello {name", wantErr: "unclosed '{' at offset 6"},
+		{name: "single closing brace", template: "hello }", wantErr: "single '}' at offset 6"},
+		{name: "empty variable", template: "hello {}", wantErr: "invalid variable"},
+		{name: "variable with space", template: "hello {first name}", wantErr: "invalid variable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars, err := templateVariables(tt.template, TemplateFormatFString)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("templateVariables error = %v, want %q", err, tt.wantErr)
+				}
+				if _, err := renderFString(tt.template, tt.values); err == nil {
+					t.Fatal("renderFString succeeded, want error")
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(vars, tt.wantVars) {
+				t.Fatalf("templateVariables = %v, %v, want %v", vars, err, tt.wantVars)
+			}
+			got, err := renderFString(tt.template, tt.values)
+			if err != nil || got != tt.want {
+				t.Fatalf("renderFString = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestFStringMissingVariables 检查缺少变量时返回列出全部缺少变量的 *ValidationError。
+func TestFStringMissingVariables(t *testing.T) {
+	p := NewFStringPromptTemplate("{a} {b} {c}", []string{"a", "b", "c"})
+	_, err := p.Format(map[string]any{"b": 1})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Format error = %v, want *ValidationError", err)
+	}
+	if !reflect.DeepEqual(verr.Missing, []string{"a", "c"}) {
+		t.Errorf("Missing = %v, want [a c]", verr.Missing)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		prompt      PromptTemplate
+		wantMissing []string
+		wantUnused  []string
+	}{
+		{name: "consistent", prompt: NewFStringPromptTemplate("{a}", []string{"a"})},
+		{name: "partial variable", prompt: NewFStringPromptTemplate("{a}{b}", []string{"a", "b"}).WithPartialVariables(map[string]any{"b": 1})},
+		{name: "undeclared", prompt: NewFStringPromptTemplate("{a}{b}", []string{"a"}), wantMissing: []string{"b"}},
+		{name: "unused", prompt: NewPromptTemplate("{{.a}}", []string{"a", "z"}), wantUnused: []string{"z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.prompt.Validate()
+			if tt.wantMissing == nil && tt.wantUnused == nil {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate error = %v, want *ValidationError", err)
+			}
+			if !reflect.DeepEqual(verr.Missing, tt.wantMissing) || !reflect.DeepEqual(verr.Unused, tt.wantUnused) {
+				t.Errorf("Validate = missing %v unused %v, want %v %v", verr.Missing, verr.Unused, tt.wantMissing, tt.wantUnused)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package structured
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema 是以 map 表示的 JSON Schema，可以直接序列化后发送给模型。
+type Schema = map[string]any
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// SchemaFor 通过反射为 v 的类型生成 JSON Schema。
+//
+// 结构体字段按照 encoding/json 的规则命名：使用 json 标签中的名称，
+// 忽略 json:"-" 以及未导出的字段，带 omitempty 或者是指针类型的字段不会出现在 required 中。
+// 另外支持两个标签：description:"..." 为字段添加说明，enum:"a,b,c" 限定取值。
+// enum 只能用于字符串、整数、浮点数和布尔类型的字段，取值按字段类型解析。
+func SchemaFor(v any) (Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("structured: cannot derive schema for nil")
+	}
+	return schemaForType(t, map[reflect.Type]bool{})
+}
+
+// SchemaOf 是 SchemaFor 的泛型版本。
+func SchemaOf[T any]() (Schema, error) {
+	return SchemaFor(new(T))
+}
+
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) (Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return Schema{"type": "string", "format": "date-time"}, nil
+	case rawJSONType:
+		return Schema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}, nil
+	case reflect.Bool:
+		return Schema{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}, nil
+	case reflect.Interface:
+		return Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		// []byte 按 encoding/json 的规则编码为 base64 字符串。
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return Schema{"type": "string"}, nil
+		}
+		items, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return Schema{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("structured: unsupported map key type %s", t.Key())
+		}
+		values, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return Schema{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return schemaForStruct(t, visiting)
+	}
+	return nil, fmt.Errorf("structured: unsupported type %s", t)
+}
+
+func schemaForStruct(t reflect.Type, visiting map[reflect.Type]bool) (Schema, error) {
+	if visiting[t] {
+		return nil, fmt.Errorf("structured: recursive type %s is not supported", t)
+	}
+	// 自定义了 MarshalJSON 的类型无法推断结构，不做约束。
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return Schema{}, nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := Schema{}
+	required := []string{}
+	if err := collectFields(t, visiting, properties, &required); err != nil {
+		return nil, err
+	}
+	return Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
+
+// collectFields 收集结构体的字段，匿名嵌入且没有 json 名称的结构体字段会被展开。
+func collectFields(t reflect.Type, visiting map[reflect.Type]bool, properties Schema, required *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := collectFields(ft, visiting, properties, required); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := schemaForType(f.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if desc := f.Tag.Get("description"); desc != "" {
+			prop["description"] = desc
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			values, err := enumValues(prop, enum)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			prop["enum"] = values
+		}
+		properties[name] = prop
+
+		optional := strings.Contains(","+opts+",", ",omitempty,") || f.Type.Kind() == reflect.Pointer
+		if !optional {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// enumValues 把 enum 标签中逗号分隔的取值按字段的 JSON 类型解析，
+// 数字统一保存为 float64，与 encoding/json 解码得到的值一致，便于 Validate 比较。
+func enumValues(prop Schema, enum string) ([]any, error) {
+	typ, _ := prop["type"].(string)
+	values := []any{}
+	for _, raw := range strings.Split(enum, ",") {
+		raw = strings.TrimSpace(raw)
+		var (
+			v   any
+			err error
+		)
+		switch typ {
+		case "string":
+			v = raw
+		case "integer":
+			var n int64
+			n, err = strconv.ParseInt(raw, 10, 64)
+			v = float64(n)
+		case "number":
+			v, err = strconv.ParseFloat(raw, 64)
+		case "boolean":
+			v, err = strconv.ParseBool(raw)
+		default:
+			return nil, fmt.Errorf("structured: enum is not supported for type %q", typ)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("structured: invalid %s enum value %q", typ, raw)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
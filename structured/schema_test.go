@@ -0,0 +1,110 @@
+package structured
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSchemaForEnum 检查 enum 标签的取值按字段类型解析。
+func TestSchemaForEnum(t *testing.T) {
+	type target struct {
+		Level   string   `json:"level" enum:"low, medium,high"`
+		Count   int      `json:"count" enum:"1,2,3"`
+		Ratio   *float64 `json:"ratio" enum:"0.5,1.5"`
+		Enabled bool     `json:"enabled" enum:"true"`
+	}
+	schema, err := SchemaFor(target{})
+	if err != nil {
+		t.Fatalf("SchemaFor: %v", err)
+	}
+	properties := schema["properties"].(Schema)
+	tests := []struct {
+		name string
+		want []any
+	}{
+		{"level", []any{"low", "medium", "high"}},
+		{"count", []any{1.0, 2.0, 3.0}},
+		{"ratio", []any{0.5, 1.5}},
+		{"enabled", []any{true}},
+	}
+	for _, tt := range tests {
+		got := properties[tt.name].(Schema)["enum"]
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s enum = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestSchemaForEnumErrors 检查 enum 用在不支持的类型上或取值无法解析时返回错误。
+func TestSchemaForEnumErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		wantErr string
+	}{
+		{"invalid integer", struct {
+			N int `json:"n" enum:"1,two"`
+		}{}, `invalid integer enum value "two"`},
+		{"invalid boolean", struct {
+			B bool `json:"b" enum:"yes"`
+		}{}, `invalid boolean enum value "yes"`},
+		{"slice", struct {
+			S []string `json:"s" enum:"a,b"`
+		}{}, `enum is not supported for type "array"`},
+		{"struct", struct {
+			S struct{ A string } `json:"s" enum:"a"`
+		}{}, `enum is not supported for type "object"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SchemaFor(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("SchemaFor error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateEnum 检查非字符串的 enum 能匹配 JSON 解码得到的值。
+func TestValidateEnum(t *testing.T) {
+	type target struct {
+		Level string `json:"level" enum:"low,high"`
+		Count int    `json:"count" enum:"1,2"`
+		Flag  bool   `json:"flag" enum:"true"`
+	}
+	schema, err := SchemaFor(target{})
+	if err != nil {
+		t.Fatalf("SchemaFor: %v", err)
+	}
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"valid", `{"level":"low","count":2,"flag":true}`, ""},
+		{"string not in enum", `{"level":"mid","count":1,"flag":true}`, "$.level: value mid is not one of"},
+		{"integer not in enum", `{"level":"low","count":3,"flag":true}`, "$.count: value 3 is not one of"},
+		{"integer as string", `{"level":"low","count":"1","flag":true}`, "$.count: value 1 is not one of"},
+		{"boolean not in enum", `{"level":"low","count":1,"flag":false}`, "$.flag: value false is not one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.input), &value); err != nil {
+				t.Fatal(err)
+			}
+			err := Validate(schema, value)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
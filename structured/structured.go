@@ -0,0 +1,166 @@
+// Package structured 让模型按照 Go 结构体的形状输出 JSON，并把结果解码、校验到结构体中。
+//
+//	type Answer struct {
+//		City        string  `json:"city"`
+//		Temperature float64 `json:"temperature" description:"摄氏度"`
+//	}
+//	answer, err := structured.Generate[Answer](ctx, llm, []llms.Message{
+//		llms.UserMessage("上海今天多少度？"),
+//	})
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// DefaultMaxRetries 是输出不合法时默认的重新提示次数。
+const DefaultMaxRetries = 2
+
+// ErrInvalidOutput 表示重试之后模型的输出仍然无法解码或没有通过校验。
+var ErrInvalidOutput = errors.New("structured: model output does not match schema")
+
+// Validator 可以由目标类型实现，用于在 schema 校验之外做业务层面的校验。
+// 返回的错误会被反馈给模型，作为重新生成的依据。
+type Validator interface {
+	Validate() error
+}
+
+// OutputError 记录最后一次不合法的输出以及原因，它包装了 ErrInvalidOutput。
+type OutputError struct {
+	Output   string // 模型最后一次的原始输出
+	Attempts int    // 总共尝试的次数
+	Err      error  // 最后一次解码或校验失败的原因
+}
+
+func (e *OutputError) Error() string {
+	return fmt.Sprintf("%v after %d attempts: %v", ErrInvalidOutput, e.Attempts, e.Err)
+}
+
+func (e *OutputError) Unwrap() []error {
+	return []error{ErrInvalidOutput, e.Err}
+}
+
+// Option 是 Generate 的函数选项。
+type Option func(*options)
+
+type options struct {
+	maxRetries  int
+	callOptions []llms.CallOption
+}
+
+// WithMaxRetries 设置输出不合法时重新提示模型的最大次数，0 表示不重试。
+func WithMaxRetries(n int) Option {
+	return func(o *options) {
+		o.maxRetries = n
+	}
+}
+
+// WithCallOptions 设置传给模型的调用选项，例如温度、最大 token 数等。
+func WithCallOptions(opts ...llms.CallOption) Option {
+	return func(o *options) {
+		o.callOptions = append(o.callOptions, opts...)
+	}
+}
+
+// Generate 要求模型输出符合 T 的 JSON Schema 的结果，并解码到 T 中。
+//
+// schema 会同时写入提示词和调用选项（DeepSeek 开启 json_object 模式，Ollama 把 schema 作为 format）。
+// 输出无法解码、没有通过 schema 校验或者 T 实现的 Validator 返回错误时，
+// 会把错误原因反馈给模型重新生成，最多重试 WithMaxRetries 次。
+func Generate[T any](ctx context.Context, model llms.ChatModel, messages []llms.Message, opts ...Option) (T, error) {
+	var zero T
+	o := &options{maxRetries: DefaultMaxRetries}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	schema, err := SchemaOf[T]()
+	if err != nil {
+		return zero, err
+	}
+	instructions, err := FormatInstructions(schema)
+	if err != nil {
+		return zero, err
+	}
+
+	conversation := append([]llms.Message{}, messages...)
+	conversation = append(conversation, llms.SystemMessage(instructions))
+	callOpts := append([]llms.CallOption{llms.WithJSONSchema(schema)}, o.callOptions...)
+
+	var lastErr *OutputError
+	for attempt := 1; attempt <= o.maxRetries+1; attempt++ {
+		resp, err := model.GenerateContent(ctx, conversation, callOpts...)
+		if err != nil {
+			return zero, err
+		}
+
+		result, err := Decode[T](resp.Content, schema)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = &OutputError{Output: resp.Content, Attempts: attempt, Err: err}
+
+		// 把不合法的输出和错误原因反馈给模型，要求它修正。
+		conversation = append(conversation,
+			llms.AssistantMessage(resp.Content),
+			llms.UserMessage(fmt.Sprintf(
+				"The previous output was invalid: %v\nReply again with only a JSON value that matches the schema.", err)),
+		)
+	}
+	return zero, lastErr
+}
+
+// Decode 从模型输出中提取 JSON，按 schema 校验后解码到 T 中。
+// 输出被 ``` 代码块包裹时会自动去掉代码块标记。
+func Decode[T any](output string, schema Schema) (T, error) {
+	var result T
+	raw := extractJSON(output)
+
+	var generic any
+	if err := json.Unmarshal([]byte(raw), &generic); err != nil {
+		return result, fmt.Errorf("output is not valid JSON: %w", err)
+	}
+	if err := Validate(schema, generic); err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return result, fmt.Errorf("failed to decode output: %w", err)
+	}
+	// 指针的方法集包含值接收者的方法，两种接收者都能匹配。
+	if v, ok := any(&result).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// FormatInstructions 生成要求模型按 schema 输出 JSON 的提示词。
+// DeepSeek 的 JSON 模式要求提示词中出现 "json" 字样。
+func FormatInstructions(schema Schema) (string, error) {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("structured: failed to marshal schema: %w", err)
+	}
+	return "Respond with only a JSON value, without any explanation or markdown, " +
+		"that conforms to the following JSON Schema:\n" + string(schemaJSON), nil
+}
+
+// extractJSON 去掉模型输出中可能存在的 ```json 代码块标记和首尾空白。
+func extractJSON(output string) string {
+	s := strings.TrimSpace(output)
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```")
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			s = s[i+1:]
+		}
+		s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,139 @@
+package structured
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zideajang/langChaingo/llms"
+)
+
+// fakeModel 按顺序返回预设的输出，并记录每次收到的消息。
+type fakeModel struct {
+	outputs []string
+	calls   [][]llms.Message
+}
+
+func (m *fakeModel) GenerateContent(ctx context.Context, messages []llms.Message, opts ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.calls = append(m.calls, messages)
+	if len(m.calls) > len(m.outputs) {
+		return nil, errors.New("no more outputs")
+	}
+	return &llms.ContentResponse{Content: m.outputs[len(m.calls)-1]}, nil
+}
+
+type weather struct {
+	City  string `json:"city"`
+	Level string `json:"level" enum:"low,high"`
+}
+
+func (w weather) Validate() error {
+	if w.City == "" {
+		return errors.New("city must not be empty")
+	}
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name       string
+		outputs    []string
+		maxRetries int
+		wantCalls  int
+		want       weather
+		wantErr    string // 重试用完之后 OutputError 中的原因
+	}{
+		{
+			name:      "valid first time",
+			outputs:   []string{`{"city":"上海","level":"low"}`},
+			wantCalls: 1,
+			want:      weather{City: "上海", Level: "low"},
+		},
+		{
+			name:      "code fence",
+			outputs:   []string{"```json\n{\"city\":\"上海\",\"level\":\"high\"}\n```"},
+			wantCalls: 1,
+			want:      weather{City: "上海", Level: "high"},
+		},
+		{
+			name:       "retries invalid json",
+			maxRetries: DefaultMaxRetries,
+			outputs:    []string{`not json`, `{"city":"上海","level":"low"}`},
+			wantCalls:  2,
+			want:       weather{City: "上海", Level: "low"},
+		},
+		{
+			name:       "retries schema violation",
+			maxRetries: DefaultMaxRetries,
+			outputs:    []string{`{"city":"上海","level":"mid"}`, `{"city":"上海","level":"high"}`},
+			wantCalls:  2,
+			want:       weather{City: "上海", Level: "high"},
+		},
+		{
+			name:       "retries validator error",
+			maxRetries: DefaultMaxRetries,
+			outputs:    []string{`{"city":"","level":"low"}`, `{"city":"北京","level":"low"}`},
+			wantCalls:  2,
+			want:       weather{City: "北京", Level: "low"},
+		},
+		{
+			name:       "gives up after max retries",
+			outputs:    []string{`{}`, `{"city":""}`, `{"city":"","level":"low"}`},
+			maxRetries: 1,
+			wantCalls:  2,
+			wantErr:    `missing required property "level"`,
+		},
+		{
+			name:       "no retries",
+			outputs:    []string{`[]`},
+			maxRetries: 0,
+			wantCalls:  1,
+			wantErr:    "expected object",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &fakeModel{outputs: tt.outputs}
+			got, err := Generate[weather](context.Background(), model,
+				[]llms.Message{llms.UserMessage("上海天气如何？")}, WithMaxRetries(tt.maxRetries))
+			if len(model.calls) != tt.wantCalls {
+				t.Errorf("model called %d times, want %d", len(model.calls), tt.wantCalls)
+			}
+			if tt.wantErr != "" {
+				var outErr *OutputError
+				if !errors.As(err, &outErr) || !errors.Is(err, ErrInvalidOutput) {
+					t.Fatalf("Generate error = %v, want *OutputError", err)
+				}
+				if outErr.Attempts != tt.wantCalls || !strings.Contains(outErr.Err.Error(), tt.wantErr) {
+					t.Errorf("OutputError = %+v, want %d attempts and %q", outErr, tt.wantCalls, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Generate = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestGenerateFeedback 检查重新提示时带上了上一次的输出和错误原因。
+func TestGenerateFeedback(t *testing.T) {
+	model := &fakeModel{outputs: []string{`{"city":"上海","level":"mid"}`, `{"city":"上海","level":"low"}`}}
+	if _, err := Generate[weather](context.Background(), model, []llms.Message{llms.UserMessage("q")}); err != nil {
+		t.Fatal(err)
+	}
+	retry := model.calls[1]
+	if len(retry) != 4 {
+		t.Fatalf("retry messages = %+v, want user, system, assistant, user", retry)
+	}
+	if retry[1].Role != llms.RoleSystem || !strings.Contains(retry[1].Content, "JSON Schema") {
+		t.Errorf("instructions = %+v", retry[1])
+	}
+	if retry[2].Role != llms.RoleAssistant || retry[2].Content != `{"city":"上海","level":"mid"}` {
+		t.Errorf("previous output = %+v", retry[2])
+	}
+	if retry[3].Role != llms.RoleUser || !strings.Contains(retry[3].Content, "$.level: value mid is not one of") {
+		t.Errorf("feedback = %+v", retry[3])
+	}
+}
@@ -0,0 +1,147 @@
+package structured
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Validate 检查由 encoding/json 解码得到的值（map[string]any、[]any、string、float64、bool、nil）
+// 是否满足 schema。只支持 SchemaFor 生成的关键字：type、properties、required、
+// additionalProperties、items、enum，其余关键字会被忽略。
+func Validate(schema Schema, value any) error {
+	var errs []string
+	validate(schema, value, "$", &errs)
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func validate(schema Schema, value any, path string, errs *[]string) {
+	if len(schema) == 0 {
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok && !inEnum(enum, value) {
+		*errs = append(*errs, fmt.Sprintf("%s: value %v is not one of %v", path, value, enum))
+		return
+	}
+
+	typ, _ := schema["type"].(string)
+	switch typ {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected object, got %s", path, typeName(value)))
+			return
+		}
+		validateObject(schema, obj, path, errs)
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected array, got %s", path, typeName(value)))
+			return
+		}
+		if items, ok := schema["items"].(Schema); ok {
+			for i, item := range arr {
+				validate(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected string, got %s", path, typeName(value)))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected boolean, got %s", path, typeName(value)))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected number, got %s", path, typeName(value)))
+		}
+	case "integer":
+		if f, ok := value.(float64); !ok || f != math.Trunc(f) {
+			*errs = append(*errs, fmt.Sprintf("%s: expected integer, got %s", path, typeName(value)))
+		}
+	}
+}
+
+func validateObject(schema Schema, obj map[string]any, path string, errs *[]string) {
+	properties, _ := schema["properties"].(Schema)
+	required := requiredNames(schema["required"])
+	for _, name := range required {
+		if _, ok := obj[name]; !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: missing required property %q", path, name))
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if prop, ok := properties[k].(Schema); ok {
+			// 可选字段为 null 时视为未提供，必填字段为 null 时按类型不匹配报错。
+			if obj[k] == nil && !slices.Contains(required, k) {
+				continue
+			}
+			validate(prop, obj[k], path+"."+k, errs)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				*errs = append(*errs, fmt.Sprintf("%s: unexpected property %q", path, k))
+			}
+		case Schema:
+			validate(extra, obj[k], path+"."+k, errs)
+		}
+	}
+}
+
+// requiredNames 同时兼容 []string（SchemaFor 生成）和 []any（从 JSON 解码）两种形式。
+func requiredNames(v any) []string {
+	switch names := v.(type) {
+	case []string:
+		return names
+	case []any:
+		out := make([]string, 0, len(names))
+		for _, n := range names {
+			if s, ok := n.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func inEnum(enum []any, value any) bool {
+	for _, e := range enum {
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
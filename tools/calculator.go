@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zideajang/langChaingo/agents"
+)
+
+// ErrInvalidExpression 表示计算器无法解析输入的表达式。
+var ErrInvalidExpression = errors.New("tools: invalid expression")
+
+// Calculator 计算算术表达式，支持 + - * / % ^、括号、pi 和 e 两个常量，
+// 以及 sqrt、abs、ln、log10、sin、cos、tan、floor、ceil、round 等函数。
+// 表达式由自带的解析器计算，不会执行任何代码。
+type Calculator struct{}
+
+var _ agents.Tool = Calculator{}
+
+// NewCalculator 创建一个计算器工具。
+func NewCalculator() Calculator { return Calculator{} }
+
+func (Calculator) Name() string { return "calculator" }
+
+func (Calculator) Description() string {
+	return "Evaluates an arithmetic expression and returns the result. " +
+		"Supports + - * / % ^, parentheses, the constants pi and e, and the functions " +
+		"sqrt, abs, ln, log10, sin, cos, tan, floor, ceil and round. Example input: (3 + 4) * 2 ^ 3"
+}
+
+func (Calculator) Call(_ context.Context, input string) (string, error) {
+	v, err := Evaluate(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64), nil
+}
+
+var calculatorFuncs = map[string]func(float64) float64{
+	"sqrt":  math.Sqrt,
+	"abs":   math.Abs,
+	"ln":    math.Log,
+	"log10": math.Log10,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"round": math.Round,
+}
+
+var calculatorConsts = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// Evaluate 计算算术表达式的值。
+func Evaluate(expr string) (float64, error) {
+	p := &exprParser{s: expr}
+	v, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return 0, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%w: result is not a finite number", ErrInvalidExpression)
+	}
+	return v, nil
+}
+
+// maxExprDepth 是表达式允许的最大嵌套深度，括号、一元正负号和乘方都会增加深度，
+// 避免模型给出的深度嵌套输入耗尽调用栈。
+const maxExprDepth = 256
+
+// exprParser 是一个递归下降解析器，优先级从低到高为：加减、乘除取余、一元正负、乘方（右结合）。
+type exprParser struct {
+	s     string
+	pos   int
+	depth int
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidExpression, fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume 跳过空白后如果下一个字符是 c 则消费它。
+func (p *exprParser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseExpr() (float64, error) {
+	v, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.consume('+'):
+			r, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			v += r
+		case p.consume('-'):
+			r, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			v -= r
+		default:
+			return v, nil
+		}
+	}
+}
+
+func (p *exprParser) parseTerm() (float64, error) {
+	v, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		var op byte
+		switch {
+		case p.consume('*'):
+			op = '*'
+		case p.consume('/'):
+			op = '/'
+		case p.consume('%'):
+			op = '%'
+		default:
+			return v, nil
+		}
+		r, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			v *= r
+		case '/':
+			if r == 0 {
+				return 0, p.errorf("division by zero")
+			}
+			v /= r
+		case '%':
+			if r == 0 {
+				return 0, p.errorf("division by zero")
+			}
+			v = math.Mod(v, r)
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (float64, error) {
+	// 所有的递归都会经过 parseUnary，在这里统一限制深度。
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExprDepth {
+		return 0, p.errorf("expression is nested too deeply")
+	}
+
+	switch {
+	case p.consume('-'):
+		v, err := p.parseUnary()
+		return -v, err
+	case p.consume('+'):
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *exprParser) parsePower() (float64, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return 0, err
+	}
+	if p.consume('^') {
+		// 乘方是右结合的，并且指数可以带符号，例如 2^-1。
+		exp, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exp), nil
+	}
+	return base, nil
+}
+
+func (p *exprParser) parsePrimary() (float64, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return 0, p.errorf("unexpected end of expression")
+	}
+
+	c := p.s[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		v, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if !p.consume(')') {
+			return 0, p.errorf("missing )")
+		}
+		return v, nil
+	case c >= '0' && c <= '9' || c == '.':
+		return p.parseNumber()
+	case unicode.IsLetter(rune(c)):
+		return p.parseIdent()
+	}
+	return 0, p.errorf("unexpected %q", string(c))
+}
+
+func (p *exprParser) parseNumber() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		isExp := (c == 'e' || c == 'E') && p.pos+1 < len(p.s) &&
+			(p.s[p.pos+1] >= '0' && p.s[p.pos+1] <= '9' || p.s[p.pos+1] == '-' || p.s[p.pos+1] == '+')
+		switch {
+		case c >= '0' && c <= '9' || c == '.' || c == '_':
+			p.pos++
+		case isExp:
+			p.pos += 2
+		default:
+			return p.number(start)
+		}
+	}
+	return p.number(start)
+}
+
+func (p *exprParser) number(start int) (float64, error) {
+	v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %q", p.s[start:p.pos])
+	}
+	return v, nil
+}
+
+func (p *exprParser) parseIdent() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.s) && (unicode.IsLetter(rune(p.s[p.pos])) || unicode.IsDigit(rune(p.s[p.pos]))) {
+		p.pos++
+	}
+	name := strings.ToLower(p.s[start:p.pos])
+
+	if fn, ok := calculatorFuncs[name]; ok {
+		if !p.consume('(') {
+			return 0, p.errorf("expected ( after %s", name)
+		}
+		arg, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if !p.consume(')') {
+			return 0, p.errorf("missing )")
+		}
+		return fn(arg), nil
+	}
+	if v, ok := calculatorConsts[name]; ok {
+		return v, nil
+	}
+	return 0, p.errorf("unknown identifier %q", name)
+}
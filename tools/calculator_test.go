@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 / 4", 2.5},
+		{"10 % 4", 2},
+		{"2 ^ 3 ^ 2", 512},
+		{"2^-1", 0.5},
+		{"-2 ^ 2", -4},
+		{"--3", 3},
+		{"+4", 4},
+		{"1e3 + 1_000", 2000},
+		{"sqrt(16) + abs(-2)", 6},
+		{"round(2 * pi)", 6},
+		{"ln(e)", 1},
+		{"SQRT(9)", 3},
+	}
+	for _, tt := range tests {
+		got, err := Evaluate(tt.expr)
+		if err != nil {
+			t.Errorf("Evaluate(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Evaluate(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 / 0",
+		"5 % 0",
+		"foo(1)",
+		"sqrt 4",
+		"1 2",
+		"sqrt(-1)",
+		"os.Exit(1)",
+		strings.Repeat("(", 10000) + "1" + strings.Repeat(")", 10000),
+		strings.Repeat("-", 10000) + "1",
+		"2" + strings.Repeat("^2", 10000),
+	}
+	for _, expr := range tests {
+		name := expr
+		if len(name) > 20 {
+			name = name[:20] + "..."
+		}
+		if _, err := Evaluate(expr); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("Evaluate(%q) error = %v, want ErrInvalidExpression", name, err)
+		}
+	}
+}
+
+func TestEvaluateNestingLimit(t *testing.T) {
+	expr := strings.Repeat("(", maxExprDepth-1) + "1" + strings.Repeat(")", maxExprDepth-1)
+	if got, err := Evaluate(expr); err != nil || got != 1 {
+		t.Errorf("Evaluate(nested %d) = %v, %v, want 1", maxExprDepth-1, got, err)
+	}
+}
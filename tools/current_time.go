@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zideajang/langChaingo/agents"
+)
+
+// CurrentTime 返回当前的日期和时间。输入可以是 IANA 时区名称，例如 Asia/Shanghai，
+// 为空时使用 WithLocation 设置的时区。
+type CurrentTime struct {
+	opts options
+}
+
+var _ agents.Tool = (*CurrentTime)(nil)
+
+// NewCurrentTime 创建一个返回当前时间的工具。
+func NewCurrentTime(opts ...Option) *CurrentTime {
+	return &CurrentTime{opts: newOptions(opts)}
+}
+
+func (t *CurrentTime) Name() string { return "current_time" }
+
+func (t *CurrentTime) Description() string {
+	return "Returns the current date, time and weekday. " +
+		"The input is an optional IANA time zone name such as Asia/Shanghai or America/New_York; leave it empty for the default time zone."
+}
+
+func (t *CurrentTime) Call(_ context.Context, input string) (string, error) {
+	loc := t.opts.location
+	if name := strings.TrimSpace(input); name != "" {
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return "", fmt.Errorf("tools: unknown time zone %q", name)
+		}
+		loc = l
+	}
+	now := time.Now().In(loc)
+	return fmt.Sprintf("%s (%s, %s)", now.Format(time.RFC3339), now.Weekday(), loc), nil
+}
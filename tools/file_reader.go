@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zideajang/langChaingo/agents"
+)
+
+// ErrOutsideRoot 表示请求读取的文件不在 FileReader 的根目录内。
+var ErrOutsideRoot = errors.New("tools: path is outside the allowed root directory")
+
+// FileReader 读取根目录内的文本文件。输入是相对于根目录的路径，
+// 通过 .. 或符号链接指向根目录之外的路径都会被拒绝。
+//
+// 打开文件之后会再解析一次路径，确认打开的仍是根目录内的同一个文件，
+// 以拒绝在检查和打开之间被替换为符号链接的路径。如果路径在两次检查之间被替换后又被换回，
+// 仍然无法发现，因此不要让不可信的进程同时修改根目录的内容。
+type FileReader struct {
+	root string
+	opts options
+}
+
+var _ agents.Tool = (*FileReader)(nil)
+
+// NewFileReader 创建一个只能读取 root 目录内文件的工具。
+func NewFileReader(root string, opts ...Option) (*FileReader, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("tools: invalid root directory: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("tools: invalid root directory: %w", err)
+	}
+	return &FileReader{root: resolved, opts: newOptions(opts)}, nil
+}
+
+func (r *FileReader) Name() string { return "read_file" }
+
+func (r *FileReader) Description() string {
+	return "Reads a local text file and returns its content. " +
+		"The input is the file path relative to the allowed root directory, for example: docs/readme.md"
+}
+
+func (r *FileReader) Call(_ context.Context, input string) (string, error) {
+	path, err := r.resolve(strings.TrimSpace(input))
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("tools: failed to open %s: %w", input, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("tools: failed to open %s: %w", input, err)
+	}
+	// 检查和打开之间路径可能被替换为符号链接，确认打开的文件仍是根目录内的那个文件。
+	if err := r.checkSameFile(input, info); err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("tools: %s is a directory", input)
+	}
+	b, err := io.ReadAll(io.LimitReader(f, r.opts.maxBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("tools: failed to read %s: %w", input, err)
+	}
+	return truncate(b, r.opts.maxBytes), nil
+}
+
+// resolve 把输入转换为根目录内的绝对路径，并解析符号链接确认最终的文件仍在根目录内。
+func (r *FileReader) resolve(input string) (string, error) {
+	if input == "" {
+		return "", fmt.Errorf("tools: empty path")
+	}
+	// 先把输入当作以根目录为 / 的路径清理，去掉所有跳出根目录的 ..。
+	path := filepath.Join(r.root, filepath.Clean(string(filepath.Separator)+input))
+	resolved, err := filepath.EvalSymlinks(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// 不在错误中暴露根目录的绝对路径。
+		return "", fmt.Errorf("tools: %s: %w", input, os.ErrNotExist)
+	}
+	if err != nil {
+		return "", fmt.Errorf("tools: failed to open %s: %w", input, err)
+	}
+	rel, err := filepath.Rel(r.root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideRoot, input)
+	}
+	return resolved, nil
+}
+
+// checkSameFile 重新解析 input，确认它仍指向根目录内的 info 这个文件。
+func (r *FileReader) checkSameFile(input string, info os.FileInfo) error {
+	path, err := r.resolve(strings.TrimSpace(input))
+	if err != nil {
+		return err
+	}
+	current, err := os.Stat(path)
+	if err != nil || !os.SameFile(info, current) {
+		return fmt.Errorf("%w: %s changed while it was being opened", ErrOutsideRoot, input)
+	}
+	return nil
+}
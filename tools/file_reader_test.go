@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRoot 创建根目录 root/sandbox 以及根目录之外的 root/secret.txt。
+func newTestRoot(t *testing.T) (root, outside string) {
+	t.Helper()
+	dir := t.TempDir()
+	root = filepath.Join(dir, "sandbox")
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "readme.md"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outside = filepath.Join(dir, "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root, outside
+}
+
+func TestFileReader(t *testing.T) {
+	root, _ := newTestRoot(t)
+	r, err := NewFileReader(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{"docs/readme.md", " docs/readme.md\n", "./docs/../docs/readme.md"} {
+		got, err := r.Call(context.Background(), input)
+		if err != nil || got != "hello" {
+			t.Errorf("Call(%q) = %q, %v, want hello", input, got, err)
+		}
+	}
+}
+
+func TestFileReaderStaysInRoot(t *testing.T) {
+	root, outside := newTestRoot(t)
+	r, err := NewFileReader(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// .. 和绝对路径都被当作以根目录为 / 的路径处理，不会读到根目录之外的文件。
+	for _, input := range []string{"../secret.txt", "docs/../../secret.txt", outside, "/secret.txt"} {
+		got, err := r.Call(context.Background(), input)
+		if err == nil {
+			t.Errorf("Call(%q) = %q, want error", input, got)
+		}
+		if strings.Contains(got, "secret") {
+			t.Errorf("Call(%q) read a file outside the root", input)
+		}
+	}
+}
+
+func TestFileReaderSymlinkEscape(t *testing.T) {
+	root, outside := newTestRoot(t)
+	if err := os.Symlink(outside, filepath.Join(root, "link.txt")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Dir(outside), filepath.Join(root, "linkdir")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "docs", "readme.md"), filepath.Join(root, "inside.md")); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewFileReader(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{"link.txt", "linkdir/secret.txt"} {
+		if _, err := r.Call(context.Background(), input); !errors.Is(err, ErrOutsideRoot) {
+			t.Errorf("Call(%q) error = %v, want ErrOutsideRoot", input, err)
+		}
+	}
+	// 指向根目录内文件的符号链接仍然可以读取。
+	if got, err := r.Call(context.Background(), "inside.md"); err != nil || got != "hello" {
+		t.Errorf("Call(inside.md) = %q, %v, want hello", got, err)
+	}
+}
+
+func TestFileReaderErrors(t *testing.T) {
+	root, _ := newTestRoot(t)
+	r, err := NewFileReader(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Call(context.Background(), "missing.md"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file error = %v, want os.ErrNotExist", err)
+	}
+	if _, err := r.Call(context.Background(), "docs"); err == nil {
+		t.Error("reading a directory succeeded, want error")
+	}
+	if _, err := r.Call(context.Background(), "  "); err == nil {
+		t.Error("empty path succeeded, want error")
+	}
+}
+
+func TestFileReaderMaxBytes(t *testing.T) {
+	root, _ := newTestRoot(t)
+	tests := []struct {
+		maxBytes int64
+		want     string
+	}{
+		{3, "hel\n...(truncated)"},
+		{5, "hello"},
+		// 非正数被忽略，使用 DefaultMaxBytes。
+		{0, "hello"},
+		{-1, "hello"},
+	}
+	for _, tt := range tests {
+		r, err := NewFileReader(root, WithMaxBytes(tt.maxBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := r.Call(context.Background(), "docs/readme.md")
+		if err != nil || got != tt.want {
+			t.Errorf("WithMaxBytes(%d): Call = %q, %v, want %q", tt.maxBytes, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zideajang/langChaingo/agents"
+)
+
+// ErrHostNotAllowed 表示请求的主机不在 HTTPGet 的白名单中。
+var ErrHostNotAllowed = errors.New("tools: host is not allowed")
+
+// HTTPGet 对白名单中的主机发起 GET 请求并返回响应内容。
+// 白名单中的 "example.com" 只匹配该主机，"*.example.com" 匹配它的所有子域名。
+// 重定向到白名单之外的主机同样会被拒绝。
+type HTTPGet struct {
+	allowedHosts []string
+	client       *http.Client
+	opts         options
+}
+
+var _ agents.Tool = (*HTTPGet)(nil)
+
+// NewHTTPGet 创建一个只能访问 allowedHosts 的 HTTP GET 工具。
+func NewHTTPGet(allowedHosts []string, opts ...Option) *HTTPGet {
+	t := &HTTPGet{opts: newOptions(opts)}
+	for _, h := range allowedHosts {
+		t.allowedHosts = append(t.allowedHosts, strings.ToLower(h))
+	}
+
+	// 复制调用方的客户端，只替换重定向检查，避免修改调用方的配置。
+	client := *t.opts.httpClient
+	next := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := t.checkURL(req.URL); err != nil {
+			return err
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	t.client = &client
+	return t
+}
+
+func (t *HTTPGet) Name() string { return "http_get" }
+
+func (t *HTTPGet) Description() string {
+	return "Fetches a web page or API with an HTTP GET request and returns the response body. " +
+		"The input is an absolute http or https URL. Allowed hosts: " + strings.Join(t.allowedHosts, ", ")
+}
+
+func (t *HTTPGet) Call(ctx context.Context, input string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(input))
+	if err != nil {
+		return "", fmt.Errorf("tools: invalid URL: %w", err)
+	}
+	if err := t.checkURL(u); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("tools: failed to create request: %w", err)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("tools: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, t.opts.maxBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("tools: failed to read response: %w", err)
+	}
+	// 非 2xx 的响应同样交给模型，由它判断如何处理，例如换一个 URL。
+	body := truncate(b, t.opts.maxBytes)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Sprintf("HTTP %d\n%s", resp.StatusCode, body), nil
+	}
+	return body, nil
+}
+
+// checkURL 检查 URL 的协议和主机是否允许访问。
+func (t *HTTPGet) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("tools: unsupported URL scheme %q", u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range t.allowedHosts {
+		if host == allowed {
+			return nil
+		}
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok && strings.HasSuffix(host, "."+suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+}
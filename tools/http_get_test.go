@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHTTPGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "http://example.com/", http.StatusFound)
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			io.WriteString(w, "hello world")
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tool := NewHTTPGet([]string{"127.0.0.1"}, WithMaxBytes(5))
+	ctx := context.Background()
+
+	if got, err := tool.Call(ctx, srv.URL+"/"); err != nil || got != "hello\n...(truncated)" {
+		t.Errorf("Call = %q, %v", got, err)
+	}
+	if got, err := tool.Call(ctx, srv.URL+"/missing"); err != nil || !strings.HasPrefix(got, "HTTP 404") {
+		t.Errorf("Call(/missing) = %q, %v, want HTTP 404", got, err)
+	}
+	if _, err := tool.Call(ctx, srv.URL+"/redirect"); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("redirect error = %v, want ErrHostNotAllowed", err)
+	}
+	if _, err := tool.Call(ctx, "http://localhost/"); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("localhost error = %v, want ErrHostNotAllowed", err)
+	}
+	if _, err := tool.Call(ctx, "file:///etc/passwd"); err == nil {
+		t.Error("file URL succeeded, want error")
+	}
+}
+
+func TestHTTPGetWildcardHost(t *testing.T) {
+	tool := NewHTTPGet([]string{"*.Example.com"})
+	tests := []struct {
+		host string
+		ok   bool
+	}{
+		{"api.example.com", true},
+		{"a.b.example.com", true},
+		{"example.com", false},
+		{"badexample.com", false},
+	}
+	for _, tt := range tests {
+		err := tool.checkURL(mustParseURL(t, "https://"+tt.host+"/"))
+		if (err == nil) != tt.ok {
+			t.Errorf("checkURL(%s) error = %v, want ok=%v", tt.host, err, tt.ok)
+		}
+	}
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zideajang/langChaingo/agents"
+)
+
+// ErrInvalidPath 表示 JSONPath 表达式无法解析。
+var ErrInvalidPath = errors.New("tools: invalid JSON path")
+
+// JSONPath 在 JSON 文档中按路径查询值。路径支持 $、.key、['key']、[0]、[-1]、[*] 和 .*，
+// 例如 $.items[0].name 或 $.items[*].price。只有一个结果时返回该值，
+// 使用通配符时返回由所有结果组成的数组，结果都以 JSON 编码。
+type JSONPath struct{}
+
+var _ agents.StructuredTool = JSONPath{}
+
+// NewJSONPath 创建一个 JSON 路径查询工具。
+func NewJSONPath() JSONPath { return JSONPath{} }
+
+func (JSONPath) Name() string { return "json_path" }
+
+func (JSONPath) Description() string {
+	return "Queries a JSON document with a JSONPath expression such as $.items[0].name or $.items[*].price. " +
+		`The input is a JSON object: {"json": "<the JSON document>", "path": "<the path>"}`
+}
+
+func (JSONPath) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"json": map[string]any{"type": "string", "description": "the JSON document to query"},
+			"path": map[string]any{"type": "string", "description": "the JSONPath expression, for example $.items[0].name"},
+		},
+		"required": []string{"json", "path"},
+	}
+}
+
+func (JSONPath) Call(_ context.Context, input string) (string, error) {
+	var args struct {
+		JSON json.RawMessage `json:"json"`
+		Path string          `json:"path"`
+	}
+	if err := json.Unmarshal([]byte(input), &args); err != nil {
+		return "", fmt.Errorf("tools: input must be a JSON object with json and path fields: %w", err)
+	}
+	// json 字段既可以是 JSON 字符串形式的文档，也可以直接是文档本身。
+	doc := []byte(args.JSON)
+	var s string
+	if err := json.Unmarshal(args.JSON, &s); err == nil {
+		doc = []byte(s)
+	}
+
+	var data any
+	if err := json.Unmarshal(doc, &data); err != nil {
+		return "", fmt.Errorf("tools: invalid JSON document: %w", err)
+	}
+	result, err := QueryJSONPath(data, args.Path)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("tools: failed to encode result: %w", err)
+	}
+	return string(b), nil
+}
+
+// pathSegment 是路径中的一段：对象的键、数组下标或者通配符。
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// QueryJSONPath 在由 encoding/json 解码得到的 data 中按 path 查询值。
+func QueryJSONPath(data any, path string) (any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []any{data}
+	multiple := false
+	for _, seg := range segments {
+		var next []any
+		for _, v := range values {
+			matched, err := seg.apply(v)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, matched...)
+		}
+		values = next
+		multiple = multiple || seg.wildcard
+	}
+	if multiple {
+		return values, nil
+	}
+	return values[0], nil
+}
+
+func (seg pathSegment) apply(v any) ([]any, error) {
+	switch {
+	case seg.wildcard:
+		switch x := v.(type) {
+		case []any:
+			return x, nil
+		case map[string]any:
+			// 按键排序，保证每次查询的结果顺序一致。
+			out := make([]any, 0, len(x))
+			for _, k := range slices.Sorted(maps.Keys(x)) {
+				out = append(out, x[k])
+			}
+			return out, nil
+		}
+		return nil, nil
+	case seg.isIndex:
+		arr, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("tools: cannot index %s with [%d]", jsonTypeName(v), seg.index)
+		}
+		i := seg.index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 || i >= len(arr) {
+			return nil, fmt.Errorf("tools: index %d out of range (length %d)", seg.index, len(arr))
+		}
+		return []any{arr[i]}, nil
+	default:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("tools: cannot get key %q from %s", seg.key, jsonTypeName(v))
+		}
+		item, ok := obj[seg.key]
+		if !ok {
+			return nil, fmt.Errorf("tools: key %q not found", seg.key)
+		}
+		return []any{item}, nil
+	}
+}
+
+func parsePath(path string) ([]pathSegment, error) {
+	s := strings.TrimSpace(path)
+	s = strings.TrimPrefix(s, "$")
+
+	var segments []pathSegment
+	for s != "" {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			name := s[:end]
+			if name == "" {
+				return nil, fmt.Errorf("%w: empty key in %q", ErrInvalidPath, path)
+			}
+			if name == "*" {
+				segments = append(segments, pathSegment{wildcard: true})
+			} else {
+				segments = append(segments, pathSegment{key: name})
+			}
+			s = s[end:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: missing ] in %q", ErrInvalidPath, path)
+			}
+			inner := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("%w: invalid index [%s] in %q", ErrInvalidPath, inner, path)
+				}
+				segments = append(segments, pathSegment{index: i, isIndex: true})
+			}
+		default:
+			// 允许省略开头的 $.，例如 items[0].name。
+			if len(segments) == 0 {
+				s = "." + s
+				continue
+			}
+			return nil, fmt.Errorf("%w: unexpected %q in %q", ErrInvalidPath, s, path)
+		}
+	}
+	return segments, nil
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testDocument = `{
+	"store": {"name": "shop", "open": true},
+	"items": [
+		{"name": "apple", "price": 3},
+		{"name": "pear", "price": 5}
+	],
+	"odd key": 1
+}`
+
+func TestQueryJSONPath(t *testing.T) {
+	var data any
+	if err := json.Unmarshal([]byte(testDocument), &data); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"$.store.name", "shop"},
+		{"store.open", true},
+		{"$.items[0].name", "apple"},
+		{"$.items[-1].price", 5.0},
+		{"$['odd key']", 1.0},
+		{"$.items[*].price", []any{3.0, 5.0}},
+		{"$.store.*", []any{"shop", true}},
+	}
+	for _, tt := range tests {
+		got, err := QueryJSONPath(data, tt.path)
+		if err != nil {
+			t.Errorf("QueryJSONPath(%q) error: %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("QueryJSONPath(%q) = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestQueryJSONPathErrors(t *testing.T) {
+	var data any
+	if err := json.Unmarshal([]byte(testDocument), &data); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    string
+		invalid bool
+	}{
+		{"$.missing", false},
+		{"$.items[2]", false},
+		{"$.store[0]", false},
+		{"$.items.name", false},
+		{"$.items[", true},
+		{"$.items[abc]", true},
+	}
+	for _, tt := range tests {
+		_, err := QueryJSONPath(data, tt.path)
+		if err == nil {
+			t.Errorf("QueryJSONPath(%q) succeeded, want error", tt.path)
+			continue
+		}
+		if tt.invalid && !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("QueryJSONPath(%q) error = %v, want ErrInvalidPath", tt.path, err)
+		}
+	}
+}
+
+func TestJSONPathCall(t *testing.T) {
+	// json 字段既可以是文档本身，也可以是 JSON 字符串形式的文档。
+	inputs := []string{
+		`{"json": {"a": [1, 2]}, "path": "$.a[1]"}`,
+		`{"json": "{\"a\": [1, 2]}", "path": "$.a[1]"}`,
+	}
+	for _, input := range inputs {
+		got, err := NewJSONPath().Call(context.Background(), input)
+		if err != nil || got != "2" {
+			t.Errorf("Call(%s) = %q, %v, want 2", input, got, err)
+		}
+	}
+}
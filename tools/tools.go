@@ -0,0 +1,73 @@
+// Package tools 提供 agent 常用的内置工具，它们都实现了 agents.Tool，
+// 可以直接交给 agents.NewReActAgent 或 agents.NewFunctionsAgent 使用。
+//
+// 这些工具不会执行 shell 命令，也不会对输入做 eval：计算器只解析算术表达式，
+// 文件读取限制在指定的根目录内，HTTP 请求只能访问白名单中的主机。
+package tools
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultMaxBytes 是 FileReader 和 HTTPGet 默认最多返回的字节数，避免把过长的内容交给模型。
+const DefaultMaxBytes = 64 * 1024
+
+// Option 是用于配置内置工具的函数选项。
+type Option func(*options)
+
+type options struct {
+	maxBytes   int64
+	httpClient *http.Client
+	location   *time.Location
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		maxBytes:   DefaultMaxBytes,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		location:   time.Local,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMaxBytes 设置 FileReader 和 HTTPGet 最多返回的字节数，超出的部分会被截断。
+// n 小于等于 0 时继续使用 DefaultMaxBytes。
+func WithMaxBytes(n int64) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxBytes = n
+		}
+	}
+}
+
+// WithHTTPClient 设置 HTTPGet 使用的 HTTP 客户端，默认超时时间为 30 秒。
+// client 为 nil 时继续使用默认客户端。
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		if client != nil {
+			o.httpClient = client
+		}
+	}
+}
+
+// WithLocation 设置 CurrentTime 在输入为空时使用的时区，默认为本地时区。
+// loc 为 nil 时继续使用本地时区。
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		if loc != nil {
+			o.location = loc
+		}
+	}
+}
+
+// truncate 把 b 截断到 maxBytes，并注明被截断。
+func truncate(b []byte, maxBytes int64) string {
+	if int64(len(b)) > maxBytes {
+		return string(b[:maxBytes]) + "\n...(truncated)"
+	}
+	return string(b)
+}
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNilOptionsKeepDefaults(t *testing.T) {
+	o := newOptions([]Option{WithHTTPClient(nil), WithLocation(nil), WithMaxBytes(-1)})
+	if o.httpClient == nil {
+		t.Error("WithHTTPClient(nil) cleared the default client")
+	}
+	if o.location != time.Local {
+		t.Errorf("WithLocation(nil) location = %v, want Local", o.location)
+	}
+	if o.maxBytes != DefaultMaxBytes {
+		t.Errorf("WithMaxBytes(-1) maxBytes = %d, want %d", o.maxBytes, DefaultMaxBytes)
+	}
+
+	// 以前 WithLocation(nil) 会让 Call 崩溃。
+	if _, err := NewCurrentTime(WithLocation(nil)).Call(context.Background(), ""); err != nil {
+		t.Errorf("CurrentTime.Call error: %v", err)
+	}
+}
+
+func TestCurrentTime(t *testing.T) {
+	tool := NewCurrentTime(WithLocation(time.UTC))
+	got, err := tool.Call(context.Background(), "")
+	if err != nil || !strings.HasSuffix(got, ", UTC)") {
+		t.Errorf("Call() = %q, %v, want UTC time", got, err)
+	}
+	got, err = tool.Call(context.Background(), "Asia/Shanghai")
+	if err != nil || !strings.Contains(got, "+08:00") {
+		t.Errorf("Call(Asia/Shanghai) = %q, %v", got, err)
+	}
+	if _, err := tool.Call(context.Background(), "Mars/Olympus"); err == nil {
+		t.Error("unknown time zone succeeded, want error")
+	}
+}